@@ -2,7 +2,10 @@ package main
 
 import (
 	"api/src/config"
+	"api/src/eventos"
+	"api/src/hub"
 	"api/src/router"
+	"api/src/webhooks"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,6 +16,12 @@ import (
 
 func main() {
 	config.Carregar()
+	if erro := hub.Iniciar(); erro != nil {
+		log.Fatal(erro)
+	}
+	eventos.Iniciar()
+	webhooks.Iniciar()
+
 	r := router.Gerar()
 
 	cors := handlers.CORS(
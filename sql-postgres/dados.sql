@@ -1,7 +1,7 @@
-INSERT INTO usuarios (nome, nick, email, senha) VALUES
-  ('usuario1', 'usuario_1', 'usuario1@gmail.com', '$2a$10$p5/aAQsukYGjj3Yag672M.zk.sgurKnn.QnOruHYCUy2o7NEKJBOq'),
-  ('usuario2', 'usuario_2', 'usuario2@gmail.com', '$2a$10$p5/aAQsukYGjj3Yag672M.zk.sgurKnn.QnOruHYCUy2o7NEKJBOq'),
-  ('usuario3', 'usuario_3', 'usuario3@gmail.com', '$2a$10$p5/aAQsukYGjj3Yag672M.zk.sgurKnn.QnOruHYCUy2o7NEKJBOq');
+INSERT INTO usuarios (nome, nick, email, senha, email_verificado) VALUES
+  ('usuario1', 'usuario_1', 'usuario1@gmail.com', '$2a$10$p5/aAQsukYGjj3Yag672M.zk.sgurKnn.QnOruHYCUy2o7NEKJBOq', TRUE),
+  ('usuario2', 'usuario_2', 'usuario2@gmail.com', '$2a$10$p5/aAQsukYGjj3Yag672M.zk.sgurKnn.QnOruHYCUy2o7NEKJBOq', TRUE),
+  ('usuario3', 'usuario_3', 'usuario3@gmail.com', '$2a$10$p5/aAQsukYGjj3Yag672M.zk.sgurKnn.QnOruHYCUy2o7NEKJBOq', TRUE);
 
 INSERT INTO seguidores (usuario_id, seguidor_id) VALUES
   (1, 2),
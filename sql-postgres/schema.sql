@@ -1,17 +1,60 @@
 
+DROP TABLE IF EXISTS curtidas CASCADE;
+DROP TABLE IF EXISTS republicacoes CASCADE;
+DROP TABLE IF EXISTS mencoes CASCADE;
+DROP TABLE IF EXISTS publicacoes_hashtags CASCADE;
+DROP TABLE IF EXISTS hashtags CASCADE;
+DROP TABLE IF EXISTS variantes_midia CASCADE;
+DROP TABLE IF EXISTS midias CASCADE;
+DROP TABLE IF EXISTS eventos_tempo_real CASCADE;
+DROP TABLE IF EXISTS eventos_outbox CASCADE;
+DROP TABLE IF EXISTS entregas_webhook CASCADE;
+DROP TABLE IF EXISTS webhooks CASCADE;
+DROP TABLE IF EXISTS notificacoes CASCADE;
+DROP TABLE IF EXISTS mensagens CASCADE;
+DROP TABLE IF EXISTS participantes_conversa CASCADE;
+DROP TABLE IF EXISTS conversas CASCADE;
+DROP TABLE IF EXISTS solicitacoes_seguir CASCADE;
+DROP TABLE IF EXISTS filtros_palavras CASCADE;
+DROP TABLE IF EXISTS silenciados CASCADE;
+DROP TABLE IF EXISTS bloqueios CASCADE;
+DROP TABLE IF EXISTS historico_denuncias CASCADE;
+DROP TABLE IF EXISTS denuncias CASCADE;
+DROP TABLE IF EXISTS codigos_recuperacao CASCADE;
+DROP TABLE IF EXISTS dois_fatores CASCADE;
+DROP TABLE IF EXISTS verificacoes_email CASCADE;
+DROP TABLE IF EXISTS redefinicoes_senha CASCADE;
 DROP TABLE IF EXISTS publicacoes CASCADE;
 DROP TABLE IF EXISTS seguidores CASCADE;
 DROP TABLE IF EXISTS usuarios CASCADE;
 
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE OR REPLACE FUNCTION sem_acento(texto TEXT) RETURNS TEXT AS $$
+  SELECT lower(public.unaccent('public.unaccent', texto))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
 CREATE TABLE usuarios (
   id SERIAL PRIMARY KEY,
   nome VARCHAR(50)  NOT NULL,
   nick VARCHAR(50)  NOT NULL UNIQUE,
   email VARCHAR(50) NOT NULL UNIQUE,
-  senha VARCHAR(100) NOT NULL,
+  senha VARCHAR(255) NOT NULL,
+  email_verificado BOOLEAN DEFAULT FALSE NOT NULL,
+  papel VARCHAR(20) DEFAULT 'usuario' NOT NULL CHECK (papel IN ('usuario', 'moderador', 'admin')),
+  suspenso BOOLEAN DEFAULT FALSE NOT NULL,
+  versao_sessao INTEGER DEFAULT 0 NOT NULL,
+  privado BOOLEAN DEFAULT FALSE NOT NULL,
+  bio VARCHAR(160) DEFAULT '' NOT NULL,
+  website VARCHAR(120) DEFAULT '' NOT NULL,
+  localizacao VARCHAR(30) DEFAULT '' NOT NULL,
   criado_em TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
 );
 
+CREATE INDEX usuarios_nome_trgm_idx ON usuarios USING GIN (sem_acento(nome) gin_trgm_ops);
+CREATE INDEX usuarios_nick_trgm_idx ON usuarios USING GIN (sem_acento(nick) gin_trgm_ops);
+
 CREATE TABLE seguidores (
   usuario_id  INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
   seguidor_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
@@ -24,5 +67,269 @@ CREATE TABLE publicacoes (
   conteudo   VARCHAR(500) NOT NULL,
   autor_id   INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
   curtidas   INTEGER      DEFAULT 0,
-  criado_em  TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL
-);
\ No newline at end of file
+  criado_em  TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  -- Sem chave estrangeira de propósito: a citação continua apontando para o original depois que ele é excluído,
+  -- e a API mostra o original como indisponível
+  citada_id  INTEGER,
+  busca      TSVECTOR     GENERATED ALWAYS AS (
+    setweight(to_tsvector('portuguese', titulo), 'A') || setweight(to_tsvector('portuguese', conteudo), 'B')
+  ) STORED
+);
+
+CREATE INDEX publicacoes_busca_idx ON publicacoes USING GIN (busca);
+
+-- publicacoes.curtidas é o total desta tabela, mantido junto com cada curtida e descurtida
+CREATE TABLE curtidas (
+  usuario_id     INTEGER    NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  publicacao_id  INTEGER    NOT NULL REFERENCES publicacoes(id) ON DELETE CASCADE,
+  criado_em      TIMESTAMP  DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  PRIMARY KEY (usuario_id, publicacao_id)
+);
+CREATE INDEX publicacoes_citada_idx ON publicacoes (citada_id) WHERE citada_id IS NOT NULL;
+
+CREATE TABLE redefinicoes_senha (
+  id          SERIAL PRIMARY KEY,
+  usuario_id  INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  token_hash  CHAR(64)     NOT NULL UNIQUE,
+  expira_em   TIMESTAMP    NOT NULL,
+  usado_em    TIMESTAMP,
+  criado_em   TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE verificacoes_email (
+  id          SERIAL PRIMARY KEY,
+  usuario_id  INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  token_hash  CHAR(64)     NOT NULL UNIQUE,
+  expira_em   TIMESTAMP    NOT NULL,
+  usado_em    TIMESTAMP,
+  criado_em   TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE dois_fatores (
+  usuario_id    INTEGER      PRIMARY KEY REFERENCES usuarios(id) ON DELETE CASCADE,
+  segredo       VARCHAR(128) NOT NULL,
+  ativo         BOOLEAN      DEFAULT FALSE NOT NULL,
+  ultimo_passo  BIGINT       DEFAULT 0 NOT NULL,
+  falhas        INTEGER      DEFAULT 0 NOT NULL,
+  bloqueado_ate TIMESTAMP,
+  versao_token  INTEGER      DEFAULT 0 NOT NULL,
+  criado_em     TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE codigos_recuperacao (
+  id           SERIAL PRIMARY KEY,
+  usuario_id   INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  codigo_hash  CHAR(64)     NOT NULL,
+  usado_em     TIMESTAMP
+);
+
+CREATE TABLE denuncias (
+  id                     SERIAL PRIMARY KEY,
+  denunciante_id         INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  usuario_denunciado_id  INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  publicacao_id          INTEGER      REFERENCES publicacoes(id) ON DELETE SET NULL,
+  motivo                 VARCHAR(30)  NOT NULL,
+  descricao              VARCHAR(500) DEFAULT '' NOT NULL,
+  status                 VARCHAR(20)  DEFAULT 'aberta' NOT NULL CHECK (status IN ('aberta', 'em_analise', 'resolvida')),
+  moderador_id           INTEGER      REFERENCES usuarios(id) ON DELETE SET NULL,
+  resolucao              VARCHAR(30)  CHECK (resolucao IN ('descartada', 'publicacao_removida', 'autor_suspenso')),
+  observacao             VARCHAR(500) DEFAULT '' NOT NULL,
+  criado_em              TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  resolvido_em           TIMESTAMP
+);
+
+CREATE INDEX denuncias_status_idx ON denuncias (status, criado_em);
+
+CREATE TABLE historico_denuncias (
+  id            SERIAL PRIMARY KEY,
+  denuncia_id   INTEGER      NOT NULL REFERENCES denuncias(id) ON DELETE CASCADE,
+  moderador_id  INTEGER      REFERENCES usuarios(id) ON DELETE SET NULL,
+  acao          VARCHAR(30)  NOT NULL,
+  observacao    VARCHAR(500) DEFAULT '' NOT NULL,
+  criado_em     TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE bloqueios (
+  usuario_id    INTEGER   NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  bloqueado_id  INTEGER   NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  criado_em     TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  PRIMARY KEY (usuario_id, bloqueado_id)
+);
+
+CREATE INDEX bloqueios_bloqueado_idx ON bloqueios (bloqueado_id);
+
+CREATE TABLE silenciados (
+  usuario_id     INTEGER   NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  silenciado_id  INTEGER   NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  expira_em      TIMESTAMP,
+  criado_em      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  PRIMARY KEY (usuario_id, silenciado_id)
+);
+
+CREATE TABLE filtros_palavras (
+  id          SERIAL PRIMARY KEY,
+  usuario_id  INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  palavra     VARCHAR(100) NOT NULL,
+  expira_em   TIMESTAMP,
+  criado_em   TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  UNIQUE (usuario_id, palavra)
+);
+
+CREATE TABLE solicitacoes_seguir (
+  usuario_id      INTEGER   NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  solicitante_id  INTEGER   NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  criado_em       TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  PRIMARY KEY (usuario_id, solicitante_id)
+);
+
+CREATE TABLE conversas (
+  id             SERIAL PRIMARY KEY,
+  criado_em      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  atualizado_em  TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE participantes_conversa (
+  conversa_id         INTEGER   NOT NULL REFERENCES conversas(id) ON DELETE CASCADE,
+  usuario_id          INTEGER   NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  ultima_lida_id      INTEGER   DEFAULT 0 NOT NULL,
+  PRIMARY KEY (conversa_id, usuario_id)
+);
+
+CREATE INDEX participantes_conversa_usuario_idx ON participantes_conversa (usuario_id);
+
+CREATE TABLE mensagens (
+  id            SERIAL PRIMARY KEY,
+  conversa_id   INTEGER       NOT NULL REFERENCES conversas(id) ON DELETE CASCADE,
+  remetente_id  INTEGER       NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  conteudo      VARCHAR(1000) NOT NULL,
+  criado_em     TIMESTAMP     DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX mensagens_conversa_idx ON mensagens (conversa_id, id DESC);
+
+CREATE TABLE notificacoes (
+  id             SERIAL PRIMARY KEY,
+  usuario_id     INTEGER     NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  tipo           VARCHAR(30) NOT NULL CHECK (tipo IN ('seguidor', 'curtida', 'mencao', 'solicitacao_seguir')),
+  ator_id        INTEGER     NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  publicacao_id  INTEGER     REFERENCES publicacoes(id) ON DELETE CASCADE,
+  lida           BOOLEAN     DEFAULT FALSE NOT NULL,
+  criado_em      TIMESTAMP   DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX notificacoes_usuario_idx ON notificacoes (usuario_id, lida, criado_em DESC);
+
+CREATE TABLE webhooks (
+  id           SERIAL PRIMARY KEY,
+  url          VARCHAR(500)  NOT NULL,
+  eventos      TEXT[]        NOT NULL,
+  segredo      VARCHAR(100)  NOT NULL,
+  ativo        BOOLEAN       DEFAULT TRUE NOT NULL,
+  criado_por   INTEGER       REFERENCES usuarios(id) ON DELETE SET NULL,
+  criado_em    TIMESTAMP     DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE entregas_webhook (
+  id                 SERIAL PRIMARY KEY,
+  webhook_id         INTEGER      NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  evento             VARCHAR(50)  NOT NULL,
+  payload            TEXT         NOT NULL,
+  status             VARCHAR(20)  DEFAULT 'pendente' NOT NULL CHECK (status IN ('pendente', 'entregue', 'falhou')),
+  tentativas         INTEGER      DEFAULT 0 NOT NULL,
+  proxima_tentativa  TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  ultimo_status_http INTEGER,
+  ultimo_erro        TEXT,
+  criado_em          TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  entregue_em        TIMESTAMP
+);
+
+CREATE INDEX entregas_webhook_pendentes_idx ON entregas_webhook (proxima_tentativa) WHERE status = 'pendente';
+CREATE INDEX entregas_webhook_webhook_idx ON entregas_webhook (webhook_id, id DESC);
+
+CREATE TABLE eventos_outbox (
+  id                        SERIAL PRIMARY KEY,
+  tipo                      VARCHAR(50)  NOT NULL,
+  dados                     TEXT         NOT NULL,
+  status                    VARCHAR(20)  DEFAULT 'pendente' NOT NULL CHECK (status IN ('pendente', 'processado', 'falhou')),
+  tentativas                INTEGER      DEFAULT 0 NOT NULL,
+  manipuladores_concluidos  TEXT[]       DEFAULT '{}' NOT NULL,
+  proxima_tentativa         TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  ultimo_erro               TEXT,
+  criado_em                 TIMESTAMP    DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  processado_em             TIMESTAMP
+);
+
+CREATE INDEX eventos_outbox_pendentes_idx ON eventos_outbox (proxima_tentativa) WHERE status = 'pendente';
+
+-- Eventos em tempo real grandes demais para o NOTIFY; o broker postgres notifica só o ID e apaga os antigos
+CREATE TABLE eventos_tempo_real (
+  id         BIGSERIAL PRIMARY KEY,
+  payload    TEXT      NOT NULL,
+  criado_em  TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX eventos_tempo_real_criado_em_idx ON eventos_tempo_real (criado_em);
+
+CREATE TABLE midias (
+  id             SERIAL PRIMARY KEY,
+  publicacao_id  INTEGER       REFERENCES publicacoes(id) ON DELETE CASCADE,
+  chave          VARCHAR(255)  NOT NULL,
+  url            VARCHAR(500)  NOT NULL,
+  tipo           VARCHAR(50)   NOT NULL,
+  tamanho        INTEGER       NOT NULL,
+  largura        INTEGER       DEFAULT 0 NOT NULL,
+  altura         INTEGER       DEFAULT 0 NOT NULL,
+  blurhash       VARCHAR(100)  DEFAULT '' NOT NULL,
+  ordem          SMALLINT      DEFAULT 0 NOT NULL,
+  criado_em      TIMESTAMP     DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX midias_publicacao_idx ON midias (publicacao_id, ordem);
+
+ALTER TABLE usuarios
+  ADD COLUMN avatar_id INTEGER REFERENCES midias(id) ON DELETE SET NULL,
+  ADD COLUMN banner_id INTEGER REFERENCES midias(id) ON DELETE SET NULL;
+
+CREATE TABLE variantes_midia (
+  midia_id  INTEGER       NOT NULL REFERENCES midias(id) ON DELETE CASCADE,
+  nome      VARCHAR(20)   NOT NULL,
+  chave     VARCHAR(255)  NOT NULL,
+  url       VARCHAR(500)  NOT NULL,
+  largura   INTEGER       NOT NULL,
+  altura    INTEGER       NOT NULL,
+  PRIMARY KEY (midia_id, nome)
+);
+
+CREATE TABLE hashtags (
+  id    SERIAL PRIMARY KEY,
+  nome  VARCHAR(50)  NOT NULL UNIQUE
+);
+
+CREATE TABLE publicacoes_hashtags (
+  hashtag_id     INTEGER  NOT NULL REFERENCES hashtags(id) ON DELETE CASCADE,
+  publicacao_id  INTEGER  NOT NULL REFERENCES publicacoes(id) ON DELETE CASCADE,
+  PRIMARY KEY (hashtag_id, publicacao_id)
+);
+
+CREATE INDEX publicacoes_hashtags_publicacao_idx ON publicacoes_hashtags (publicacao_id);
+CREATE INDEX publicacoes_criado_em_idx ON publicacoes (criado_em);
+
+CREATE TABLE mencoes (
+  publicacao_id  INTEGER      NOT NULL REFERENCES publicacoes(id) ON DELETE CASCADE,
+  usuario_id     INTEGER      NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  campo          VARCHAR(10)  NOT NULL CHECK (campo IN ('titulo', 'conteudo')),
+  inicio         INTEGER      NOT NULL,
+  fim            INTEGER      NOT NULL,
+  PRIMARY KEY (publicacao_id, campo, inicio)
+);
+
+CREATE INDEX mencoes_usuario_idx ON mencoes (usuario_id, publicacao_id DESC);
+
+CREATE TABLE republicacoes (
+  usuario_id     INTEGER    NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+  publicacao_id  INTEGER    NOT NULL REFERENCES publicacoes(id) ON DELETE CASCADE,
+  criado_em      TIMESTAMP  DEFAULT CURRENT_TIMESTAMP NOT NULL,
+  PRIMARY KEY (usuario_id, publicacao_id)
+);
+
+CREATE INDEX republicacoes_publicacao_idx ON republicacoes (publicacao_id);
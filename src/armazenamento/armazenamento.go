@@ -0,0 +1,49 @@
+package armazenamento
+
+import (
+	"api/src/config"
+	"errors"
+	"strings"
+)
+
+// ErrChaveInvalida é retornado quando a chave do arquivo tentaria sair da área de armazenamento
+var ErrChaveInvalida = errors.New("chave de arquivo inválida")
+
+// Armazenamento representa qualquer lugar onde os arquivos enviados pelos usuários podem ser guardados
+type Armazenamento interface {
+	Salvar(chave string, conteudo []byte, tipo string) error
+	Remover(chave string) error
+	URL(chave string) string
+}
+
+// Novo retorna o armazenamento definido nas variáveis de ambiente
+func Novo() Armazenamento {
+	if config.Armazenamento == "s3" {
+		return NovoS3(S3{
+			Endpoint:      config.S3Endpoint,
+			Regiao:        config.S3Regiao,
+			Bucket:        config.S3Bucket,
+			ChaveAcesso:   config.S3ChaveAcesso,
+			ChaveSecreta:  config.S3ChaveSecreta,
+			URLPublica:    config.S3URLPublica,
+			EstiloCaminho: config.S3EstiloCaminho,
+		})
+	}
+
+	return Local{Diretorio: config.ArmazenamentoDiretorio, URLBase: config.URLAPI + "/midias"}
+}
+
+// validarChave impede chaves vazias, absolutas ou com "..", que poderiam apontar para fora da área de armazenamento
+func validarChave(chave string) error {
+	if chave == "" || strings.HasPrefix(chave, "/") || strings.Contains(chave, "\\") {
+		return ErrChaveInvalida
+	}
+
+	for _, parte := range strings.Split(chave, "/") {
+		if parte == "" || parte == "." || parte == ".." {
+			return ErrChaveInvalida
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package armazenamento
+
+import "testing"
+
+func TestValidarChave(t *testing.T) {
+	testes := []struct {
+		chave  string
+		valida bool
+	}{
+		{"ab12.jpg", true},
+		{"publicacoes/ab12_media.jpg", true},
+		{"perfis/7/avatar..jpg", true},
+		{"", false},
+		{"/publicacoes/ab12.jpg", false},
+		{"../ab12.jpg", false},
+		{"publicacoes/../../ab12.jpg", false},
+		{"publicacoes/..", false},
+		{"publicacoes/./ab12.jpg", false},
+		{"publicacoes//ab12.jpg", false},
+		{"publicacoes/", false},
+		{"publicacoes\\ab12.jpg", false},
+		{"..\\ab12.jpg", false},
+	}
+
+	for _, teste := range testes {
+		erro := validarChave(teste.chave)
+		if teste.valida && erro != nil {
+			t.Errorf("validarChave(%q) = %v, esperado nil", teste.chave, erro)
+		}
+		if !teste.valida && erro != ErrChaveInvalida {
+			t.Errorf("validarChave(%q) = %v, esperado ErrChaveInvalida", teste.chave, erro)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package armazenamento
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local guarda os arquivos em uma pasta do servidor, servidos pela própria API em /midias
+type Local struct {
+	Diretorio string
+	URLBase   string
+}
+
+// Salvar grava o arquivo na pasta, criando as subpastas da chave quando necessário
+func (local Local) Salvar(chave string, conteudo []byte, _ string) error {
+	caminho, erro := local.caminho(chave)
+	if erro != nil {
+		return erro
+	}
+
+	if erro = os.MkdirAll(filepath.Dir(caminho), 0o755); erro != nil {
+		return erro
+	}
+
+	return os.WriteFile(caminho, conteudo, 0o644)
+}
+
+// Remover apaga o arquivo, ignorando arquivos que já não existem
+func (local Local) Remover(chave string) error {
+	caminho, erro := local.caminho(chave)
+	if erro != nil {
+		return erro
+	}
+
+	if erro = os.Remove(caminho); erro != nil && !errors.Is(erro, fs.ErrNotExist) {
+		return erro
+	}
+
+	return nil
+}
+
+// URL retorna o endereço público do arquivo
+func (local Local) URL(chave string) string {
+	return strings.TrimRight(local.URLBase, "/") + "/" + chave
+}
+
+func (local Local) caminho(chave string) (string, error) {
+	if erro := validarChave(chave); erro != nil {
+		return "", erro
+	}
+
+	return filepath.Join(local.Diretorio, filepath.FromSlash(chave)), nil
+}
+
+// ServidorLocal serve os arquivos da pasta do armazenamento local, sem listar o conteúdo das pastas
+func ServidorLocal(diretorio string) http.Handler {
+	arquivos := http.FileServer(http.Dir(diretorio))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		arquivos.ServeHTTP(w, r)
+	})
+}
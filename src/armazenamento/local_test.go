@@ -0,0 +1,56 @@
+package armazenamento
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	diretorio := t.TempDir()
+	local := Local{Diretorio: diretorio, URLBase: "http://localhost:5000/midias/"}
+
+	if erro := local.Salvar("publicacoes/ab12.jpg", []byte("conteudo"), "image/jpeg"); erro != nil {
+		t.Fatalf("Salvar: %v", erro)
+	}
+
+	conteudo, erro := os.ReadFile(filepath.Join(diretorio, "publicacoes", "ab12.jpg"))
+	if erro != nil || string(conteudo) != "conteudo" {
+		t.Fatalf("arquivo salvo = %q, %v", conteudo, erro)
+	}
+
+	if endereco := local.URL("publicacoes/ab12.jpg"); endereco != "http://localhost:5000/midias/publicacoes/ab12.jpg" {
+		t.Errorf("URL = %s", endereco)
+	}
+
+	if erro = local.Remover("publicacoes/ab12.jpg"); erro != nil {
+		t.Fatalf("Remover: %v", erro)
+	}
+	if _, erro = os.Stat(filepath.Join(diretorio, "publicacoes", "ab12.jpg")); !errors.Is(erro, fs.ErrNotExist) {
+		t.Errorf("o arquivo deveria ter sido apagado: %v", erro)
+	}
+
+	if erro = local.Remover("publicacoes/ab12.jpg"); erro != nil {
+		t.Errorf("remover um arquivo que já não existe deveria ser ignorado: %v", erro)
+	}
+}
+
+func TestLocalChaveInvalida(t *testing.T) {
+	pai := t.TempDir()
+	local := Local{Diretorio: filepath.Join(pai, "midias")}
+
+	for _, chave := range []string{"../fora.jpg", "/etc/passwd", "publicacoes\\..\\..\\fora.jpg"} {
+		if erro := local.Salvar(chave, []byte("conteudo"), "image/jpeg"); erro != ErrChaveInvalida {
+			t.Errorf("Salvar(%q) = %v, esperado ErrChaveInvalida", chave, erro)
+		}
+		if erro := local.Remover(chave); erro != ErrChaveInvalida {
+			t.Errorf("Remover(%q) = %v, esperado ErrChaveInvalida", chave, erro)
+		}
+	}
+
+	if _, erro := os.Stat(filepath.Join(pai, "fora.jpg")); !errors.Is(erro, fs.ErrNotExist) {
+		t.Error("nenhum arquivo deveria ter sido criado fora do diretório")
+	}
+}
@@ -0,0 +1,205 @@
+package armazenamento
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 guarda os arquivos em um bucket compatível com a API do S3 (AWS, MinIO, R2...), assinando
+// as requisições com AWS Signature Version 4
+type S3 struct {
+	Endpoint     string
+	Regiao       string
+	Bucket       string
+	ChaveAcesso  string
+	ChaveSecreta string
+	// URLPublica é o endereço usado nos links dos arquivos (um CDN, por exemplo); vazio usa o próprio bucket
+	URLPublica string
+	// EstiloCaminho usa endpoint/bucket/chave em vez de bucket.endpoint/chave, como o MinIO espera
+	EstiloCaminho bool
+
+	cliente *http.Client
+}
+
+// NovoS3 prepara o armazenamento S3 com os valores padrão que não foram informados
+func NovoS3(s3 S3) *S3 {
+	if s3.Regiao == "" {
+		s3.Regiao = "us-east-1"
+	}
+	if s3.Endpoint == "" {
+		s3.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", s3.Regiao)
+	}
+	s3.Endpoint = strings.TrimRight(s3.Endpoint, "/")
+	s3.cliente = &http.Client{Timeout: 30 * time.Second}
+
+	return &s3
+}
+
+// Salvar envia o arquivo para o bucket
+func (s3 *S3) Salvar(chave string, conteudo []byte, tipo string) error {
+	if erro := validarChave(chave); erro != nil {
+		return erro
+	}
+
+	cabecalhos := map[string]string{"Content-Type": tipo}
+	return s3.requisitar(http.MethodPut, chave, conteudo, cabecalhos)
+}
+
+// Remover apaga o arquivo do bucket; o S3 responde com sucesso mesmo se ele não existir
+func (s3 *S3) Remover(chave string) error {
+	if erro := validarChave(chave); erro != nil {
+		return erro
+	}
+
+	return s3.requisitar(http.MethodDelete, chave, nil, nil)
+}
+
+// URL retorna o endereço público do arquivo
+func (s3 *S3) URL(chave string) string {
+	if s3.URLPublica != "" {
+		return strings.TrimRight(s3.URLPublica, "/") + "/" + chave
+	}
+
+	return s3.endereco(chave).String()
+}
+
+func (s3 *S3) endereco(chave string) *url.URL {
+	endereco, _ := url.Parse(s3.Endpoint)
+	if s3.EstiloCaminho {
+		endereco.Path = "/" + s3.Bucket + "/" + chave
+	} else {
+		endereco.Host = s3.Bucket + "." + endereco.Host
+		endereco.Path = "/" + chave
+	}
+
+	return endereco
+}
+
+func (s3 *S3) requisitar(metodo, chave string, corpo []byte, cabecalhos map[string]string) error {
+	requisicao, erro := http.NewRequest(metodo, s3.endereco(chave).String(), bytes.NewReader(corpo))
+	if erro != nil {
+		return erro
+	}
+
+	for nome, valor := range cabecalhos {
+		requisicao.Header.Set(nome, valor)
+	}
+	s3.assinar(requisicao, corpo, time.Now().UTC())
+
+	resposta, erro := s3.cliente.Do(requisicao)
+	if erro != nil {
+		return erro
+	}
+	defer resposta.Body.Close()
+
+	if resposta.StatusCode < 200 || resposta.StatusCode > 299 {
+		detalhe, _ := io.ReadAll(io.LimitReader(resposta.Body, 1024))
+		return fmt.Errorf("o armazenamento S3 respondeu com o status %d: %s", resposta.StatusCode, detalhe)
+	}
+
+	return nil
+}
+
+// assinar adiciona os cabeçalhos de autenticação AWS Signature Version 4 na requisição
+func (s3 *S3) assinar(requisicao *http.Request, corpo []byte, agora time.Time) {
+	data := agora.Format("20060102")
+	dataHora := agora.Format("20060102T150405Z")
+	hashCorpo := hashHex(corpo)
+
+	requisicao.Header.Set("X-Amz-Date", dataHora)
+	requisicao.Header.Set("X-Amz-Content-Sha256", hashCorpo)
+
+	cabecalhosAssinados := "host;x-amz-content-sha256;x-amz-date"
+	cabecalhosCanonicos := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		requisicao.URL.Host, hashCorpo, dataHora)
+	if tipo := requisicao.Header.Get("Content-Type"); tipo != "" {
+		cabecalhosAssinados = "content-type;" + cabecalhosAssinados
+		cabecalhosCanonicos = "content-type:" + tipo + "\n" + cabecalhosCanonicos
+	}
+
+	requisicaoCanonica := strings.Join([]string{
+		requisicao.Method,
+		requisicao.URL.EscapedPath(),
+		consultaCanonica(requisicao.URL),
+		cabecalhosCanonicos,
+		cabecalhosAssinados,
+		hashCorpo,
+	}, "\n")
+
+	escopo := fmt.Sprintf("%s/%s/s3/aws4_request", data, s3.Regiao)
+	textoAssinado := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		dataHora,
+		escopo,
+		hashHex([]byte(requisicaoCanonica)),
+	}, "\n")
+
+	chave := hmacSHA256([]byte("AWS4"+s3.ChaveSecreta), data)
+	chave = hmacSHA256(chave, s3.Regiao)
+	chave = hmacSHA256(chave, "s3")
+	chave = hmacSHA256(chave, "aws4_request")
+	assinatura := hex.EncodeToString(hmacSHA256(chave, textoAssinado))
+
+	requisicao.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3.ChaveAcesso, escopo, cabecalhosAssinados, assinatura,
+	))
+}
+
+// consultaCanonica monta a query string como a assinatura espera: parâmetros em ordem, sempre com "=" e
+// codificados no formato da AWS, em que só letras, números e -_.~ ficam como estão
+func consultaCanonica(endereco *url.URL) string {
+	parametros := make(map[string][]string)
+	var nomes []string
+	for nome, valores := range endereco.Query() {
+		nome = codificarAWS(nome)
+		nomes = append(nomes, nome)
+		for _, valor := range valores {
+			parametros[nome] = append(parametros[nome], codificarAWS(valor))
+		}
+	}
+	sort.Strings(nomes)
+
+	var pares []string
+	for _, nome := range nomes {
+		valores := parametros[nome]
+		sort.Strings(valores)
+		for _, valor := range valores {
+			pares = append(pares, nome+"="+valor)
+		}
+	}
+
+	return strings.Join(pares, "&")
+}
+
+func codificarAWS(texto string) string {
+	var codificado strings.Builder
+	for _, b := range []byte(texto) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || strings.IndexByte("-_.~", b) >= 0 {
+			codificado.WriteByte(b)
+		} else {
+			fmt.Fprintf(&codificado, "%%%02X", b)
+		}
+	}
+	return codificado.String()
+}
+
+func hashHex(conteudo []byte) string {
+	hash := sha256.Sum256(conteudo)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(chave []byte, texto string) []byte {
+	mac := hmac.New(sha256.New, chave)
+	mac.Write([]byte(texto))
+	return mac.Sum(nil)
+}
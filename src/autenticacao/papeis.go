@@ -0,0 +1,51 @@
+package autenticacao
+
+import (
+	"context"
+	"net/http"
+)
+
+// Papéis que um usuário pode ter na rede social
+const (
+	PapelUsuario   = "usuario"
+	PapelModerador = "moderador"
+	PapelAdmin     = "admin"
+)
+
+// PapelValido informa se o papel é um dos papéis conhecidos pela API
+func PapelValido(papel string) bool {
+	return papel == PapelUsuario || papel == PapelModerador || papel == PapelAdmin
+}
+
+type chaveContexto string
+
+const chavePapel chaveContexto = "papel"
+
+// ComPapel retorna a requisição com o papel do usuário, como o middleware Autenticar o encontrou no banco. O papel não
+// vai no token, para que uma promoção ou um rebaixamento valham na próxima requisição.
+func ComPapel(r *http.Request, papel string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), chavePapel, papel))
+}
+
+// ExtrairPapel retorna o papel do usuário autenticado. Fora de uma rota autenticada não há papel conhecido, e o
+// usuário vale como usuário comum.
+func ExtrairPapel(r *http.Request) string {
+	papel, ok := r.Context().Value(chavePapel).(string)
+	if !ok || !PapelValido(papel) {
+		return PapelUsuario
+	}
+
+	return papel
+}
+
+// PossuiPapel informa se o usuário autenticado possui algum dos papéis informados
+func PossuiPapel(r *http.Request, papeis ...string) bool {
+	papel := ExtrairPapel(r)
+	for _, p := range papeis {
+		if p == papel {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,21 @@
+package autenticacao
+
+import (
+	"api/src/models"
+	"errors"
+	"net/http"
+)
+
+// ConferirSessao compara a sessão do token com o usuário como está no banco (ver Usuarios.BuscarSessao) e retorna o
+// status e o erro com que a requisição deve ser recusada, ou zero se a sessão ainda vale
+func ConferirSessao(usuario models.Usuario, versaoSessao uint64) (int, error) {
+	if usuario.ID == 0 || usuario.VersaoSessao != versaoSessao {
+		return http.StatusUnauthorized, errors.New("Sessão encerrada. Faça login novamente.")
+	}
+
+	if usuario.Suspenso {
+		return http.StatusForbidden, errors.New("Esta conta está suspensa.")
+	}
+
+	return 0, nil
+}
@@ -12,12 +12,30 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// CriarToken retorna um token assinado com as permissões do usuário
-func CriarToken(usuarioID uint64) (string, error) {
+// CriarToken retorna um token assinado com as permissões do usuário. A versão da sessão é a do usuário no momento
+// do login; quando ela muda no banco, todos os tokens emitidos antes deixam de valer.
+func CriarToken(usuarioID, versaoSessao uint64) (string, error) {
 	permissoes := jwt.MapClaims{
 		"authorized": true,
 		"exp":        time.Now().Add(time.Hour * 6).Unix(),
 		"usuarioID":  usuarioID,
+		"sessao":     versaoSessao,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissoes)
+	chaveSecreta := []byte(config.SecretKey)
+
+	return token.SignedString(chaveSecreta)
+}
+
+// CriarTokenMFAPendente retorna um token de curta duração que só serve para concluir o login com o código de dois fatores.
+// A versão é a dos dois fatores do usuário no momento do login; ela muda quando o login é bloqueado por excesso de falhas.
+func CriarTokenMFAPendente(usuarioID, versao uint64) (string, error) {
+	permissoes := jwt.MapClaims{
+		"authorized":  false,
+		"mfaPendente": true,
+		"exp":         time.Now().Add(time.Minute * 5).Unix(),
+		"usuarioID":   usuarioID,
+		"versao":      versao,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissoes)
 	chaveSecreta := []byte(config.SecretKey)
@@ -27,37 +45,84 @@ func CriarToken(usuarioID uint64) (string, error) {
 
 // Validartoken verifica se o token passado na requisição é valido
 func ValidarToken(r *http.Request) error {
-	tokenString := extrairToken(r)
+	_, erro := extrairPermissoes(r)
+	return erro
+}
+
+// ExtrairUsuarioID retorna o usuarioId que está salvo no token
+func ExtrairUsuarioID(r *http.Request) (uint64, error) {
+	permissoes, erro := extrairPermissoes(r)
+	if erro != nil {
+		return 0, erro
+	}
+
+	return usuarioIDDasPermissoes(permissoes)
+}
+
+// ExtrairSessao retorna o usuarioId e a versão da sessão que estão salvos no token
+func ExtrairSessao(r *http.Request) (uint64, uint64, error) {
+	permissoes, erro := extrairPermissoes(r)
+	if erro != nil {
+		return 0, 0, erro
+	}
+
+	usuarioID, erro := usuarioIDDasPermissoes(permissoes)
+	if erro != nil {
+		return 0, 0, erro
+	}
+
+	// Tokens emitidos antes da existência de versões de sessão valem como a versão inicial
+	versao, ok := permissoes["sessao"].(float64)
+	if !ok {
+		return usuarioID, 0, nil
+	}
+
+	return usuarioID, uint64(versao), nil
+}
+
+// ValidarTokenMFAPendente valida um token emitido por CriarTokenMFAPendente e retorna o usuarioId e a versão salvos nele
+func ValidarTokenMFAPendente(tokenString string) (uint64, uint64, error) {
 	token, erro := jwt.Parse(tokenString, retornarChaveDeVerificacao)
 	if erro != nil {
-		return erro
+		return 0, 0, erro
+	}
+
+	permissoes, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || permissoes["mfaPendente"] != true {
+		return 0, 0, errors.New("Token inválido!")
+	}
+
+	usuarioID, erro := usuarioIDDasPermissoes(permissoes)
+	if erro != nil {
+		return 0, 0, erro
 	}
 
-	if _, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return nil
+	versao, erro := strconv.ParseUint(fmt.Sprintf("%.0f", permissoes["versao"]), 10, 64)
+	if erro != nil {
+		return 0, 0, erro
 	}
 
-	return errors.New("Token inválido!")
+	return usuarioID, versao, nil
 }
 
-// ExtrairUsuarioID retorna o usuarioId que está salvo no token
-func ExtrairUsuarioID(r *http.Request) (uint64, error) {
+// extrairPermissoes valida o token da requisição e recusa tokens que ainda aguardam o segundo fator
+func extrairPermissoes(r *http.Request) (jwt.MapClaims, error) {
 	tokenString := extrairToken(r)
 	token, erro := jwt.Parse(tokenString, retornarChaveDeVerificacao)
 	if erro != nil {
-		return 0, erro
+		return nil, erro
 	}
 
-	if permissoes, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		usuarioID, erro := strconv.ParseUint(fmt.Sprintf("%.0f", permissoes["usuarioID"]), 10, 64)
-		if erro != nil {
-			return 0, erro
-		}
-
-		return usuarioID, nil
+	permissoes, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || permissoes["authorized"] != true {
+		return nil, errors.New("Token inválido!")
 	}
 
-	return 0, errors.New("Token inválido!")
+	return permissoes, nil
+}
+
+func usuarioIDDasPermissoes(permissoes jwt.MapClaims) (uint64, error) {
+	return strconv.ParseUint(fmt.Sprintf("%.0f", permissoes["usuarioID"]), 10, 64)
 }
 
 func extrairToken(r *http.Request) string {
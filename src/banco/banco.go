@@ -3,10 +3,17 @@ package banco
 import (
 	"api/src/config"
 	"database/sql"
+	"sync"
 
 	_ "github.com/go-sql-driver/mysql" // Driver
 )
 
+var (
+	compartilhada      *sql.DB
+	erroCompartilhada  error
+	abrirCompartilhada sync.Once
+)
+
 // Conectar abre a conexão com o banco de dados e a retorna
 func Conectar() (*sql.DB, error) {
 	db, erro := sql.Open("postgres", config.StringConexaoBanco)
@@ -20,4 +27,14 @@ func Conectar() (*sql.DB, error) {
 	}
 
 	return db, nil
-}
\ No newline at end of file
+}
+
+// Compartilhada retorna um pool de conexões aberto uma única vez e reaproveitado por toda a API, para as consultas
+// feitas a cada requisição. Ao contrário de Conectar, quem recebe o pool não deve fechá-lo.
+func Compartilhada() (*sql.DB, error) {
+	abrirCompartilhada.Do(func() {
+		compartilhada, erroCompartilhada = sql.Open("postgres", config.StringConexaoBanco)
+	})
+
+	return compartilhada, erroCompartilhada
+}
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq" // driver PostgreSQL
@@ -19,6 +22,76 @@ var (
 
 	// SecretKey é a chave que vai ser usada para assinar o token
 	SecretKey []byte
+
+	// URLFrontend é o endereço do front usado nos links enviados por email
+	URLFrontend string
+
+	// URLAPI é o endereço público da API usado nos links enviados por email
+	URLAPI string
+
+	// Mailer define como os emails são enviados ("smtp" ou "arquivo")
+	Mailer string
+
+	// MailerDiretorio é a pasta onde o mailer de arquivo grava os emails
+	MailerDiretorio string
+
+	// SMTPHost, SMTPPorta, SMTPUsuario, SMTPSenha e SMTPRemetente configuram o envio via SMTP
+	SMTPHost      string
+	SMTPPorta     int
+	SMTPUsuario   string
+	SMTPSenha     string
+	SMTPRemetente string
+
+	// ValidadeRedefinicaoSenha é o tempo que um token de redefinição de senha continua válido
+	ValidadeRedefinicaoSenha time.Duration
+
+	// ValidadeVerificacaoEmail é o tempo que um token de verificação de email continua válido
+	ValidadeVerificacaoEmail time.Duration
+
+	// AlgoritmoHash é o algoritmo usado para gerar novos hashes de senha ("argon2id" ou "bcrypt")
+	AlgoritmoHash string
+
+	// BcryptCusto é o custo usado quando o algoritmo de hash é bcrypt
+	BcryptCusto int
+
+	// Argon2Memoria (em KiB), Argon2Iteracoes e Argon2Paralelismo são os parâmetros do argon2id
+	Argon2Memoria     uint32
+	Argon2Iteracoes   uint32
+	Argon2Paralelismo uint8
+
+	// EmissorTOTP é o nome exibido nos apps autenticadores para a conta de dois fatores
+	EmissorTOTP string
+
+	// ChaveTOTP é a chave AES-256 que cifra os segredos TOTP guardados no banco
+	ChaveTOTP []byte
+
+	// ExigirEmailVerificado impede que usuários sem email verificado publiquem
+	ExigirEmailVerificado bool
+
+	// Broker define como os eventos em tempo real são compartilhados ("memoria" ou "postgres")
+	Broker string
+
+	// Armazenamento define onde as imagens enviadas são guardadas ("local" ou "s3")
+	Armazenamento string
+
+	// ArmazenamentoDiretorio é a pasta usada pelo armazenamento local
+	ArmazenamentoDiretorio string
+
+	// S3Endpoint, S3Regiao, S3Bucket, S3ChaveAcesso, S3ChaveSecreta, S3URLPublica e S3EstiloCaminho configuram
+	// o armazenamento compatível com S3
+	S3Endpoint      string
+	S3Regiao        string
+	S3Bucket        string
+	S3ChaveAcesso   string
+	S3ChaveSecreta  string
+	S3URLPublica    string
+	S3EstiloCaminho bool
+
+	// TamanhoMaximoImagem é o tamanho máximo, em bytes, de cada imagem anexada a uma publicação
+	TamanhoMaximoImagem int64
+
+	// MaximoImagensPorPublicacao é quantas imagens podem ser anexadas a uma publicação
+	MaximoImagensPorPublicacao int
 )
 
 // Carregar vai inicializar as variáveis de ambiente
@@ -61,4 +134,106 @@ func Carregar() {
 
 	// Define chave secreta
 	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+
+	URLFrontend = os.Getenv("URL_FRONTEND")
+	if URLFrontend == "" {
+		URLFrontend = "http://localhost:3000"
+	}
+
+	URLAPI = os.Getenv("URL_API")
+	if URLAPI == "" {
+		URLAPI = fmt.Sprintf("http://localhost:%d", Porta)
+	}
+
+	// Configura o envio de emails (sem SMTP, os emails vão para arquivo/log)
+	Mailer = os.Getenv("MAILER")
+	if Mailer == "" {
+		Mailer = "arquivo"
+	}
+	MailerDiretorio = os.Getenv("MAILER_DIRETORIO")
+
+	SMTPHost = os.Getenv("SMTP_HOST")
+	if porta, err := strconv.Atoi(os.Getenv("SMTP_PORTA")); err == nil {
+		SMTPPorta = porta
+	} else {
+		SMTPPorta = 587
+	}
+	SMTPUsuario = os.Getenv("SMTP_USUARIO")
+	SMTPSenha = os.Getenv("SMTP_SENHA")
+	SMTPRemetente = os.Getenv("SMTP_REMETENTE")
+
+	ValidadeRedefinicaoSenha = time.Hour
+	if v, err := time.ParseDuration(os.Getenv("VALIDADE_REDEFINICAO_SENHA")); err == nil {
+		ValidadeRedefinicaoSenha = v
+	}
+
+	ValidadeVerificacaoEmail = 24 * time.Hour
+	if v, err := time.ParseDuration(os.Getenv("VALIDADE_VERIFICACAO_EMAIL")); err == nil {
+		ValidadeVerificacaoEmail = v
+	}
+
+	ExigirEmailVerificado, _ = strconv.ParseBool(os.Getenv("EXIGIR_EMAIL_VERIFICADO"))
+
+	// Parâmetros de hash de senha (valores zerados usam os padrões do algoritmo)
+	AlgoritmoHash = os.Getenv("HASH_ALGORITMO")
+	if AlgoritmoHash == "" {
+		AlgoritmoHash = "argon2id"
+	}
+	BcryptCusto, _ = strconv.Atoi(os.Getenv("BCRYPT_CUSTO"))
+	if v, err := strconv.ParseUint(os.Getenv("ARGON2_MEMORIA"), 10, 32); err == nil {
+		Argon2Memoria = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("ARGON2_ITERACOES"), 10, 32); err == nil {
+		Argon2Iteracoes = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("ARGON2_PARALELISMO"), 10, 8); err == nil {
+		Argon2Paralelismo = uint8(v)
+	}
+
+	EmissorTOTP = os.Getenv("TOTP_EMISSOR")
+	if EmissorTOTP == "" {
+		EmissorTOTP = "API Rede Social"
+	}
+
+	// Sem uma chave própria (32 bytes em base64), a chave dos segredos TOTP é derivada da SECRET_KEY
+	if chave, err := base64.StdEncoding.DecodeString(os.Getenv("TOTP_CHAVE")); err == nil && len(chave) == 32 {
+		ChaveTOTP = chave
+	} else {
+		derivada := sha256.Sum256(append([]byte("totp:"), SecretKey...))
+		ChaveTOTP = derivada[:]
+	}
+
+	// Com mais de uma instância da API, use BROKER=postgres para que todas recebam os eventos
+	Broker = os.Getenv("BROKER")
+	if Broker == "" {
+		Broker = "memoria"
+	}
+
+	// Imagens das publicações (sem S3, ficam na pasta local e são servidas em /midias)
+	Armazenamento = os.Getenv("ARMAZENAMENTO")
+	if Armazenamento == "" {
+		Armazenamento = "local"
+	}
+	ArmazenamentoDiretorio = os.Getenv("ARMAZENAMENTO_DIRETORIO")
+	if ArmazenamentoDiretorio == "" {
+		ArmazenamentoDiretorio = "uploads"
+	}
+
+	S3Endpoint = os.Getenv("S3_ENDPOINT")
+	S3Regiao = os.Getenv("S3_REGIAO")
+	S3Bucket = os.Getenv("S3_BUCKET")
+	S3ChaveAcesso = os.Getenv("S3_CHAVE_ACESSO")
+	S3ChaveSecreta = os.Getenv("S3_CHAVE_SECRETA")
+	S3URLPublica = os.Getenv("S3_URL_PUBLICA")
+	S3EstiloCaminho, _ = strconv.ParseBool(os.Getenv("S3_ESTILO_CAMINHO"))
+
+	TamanhoMaximoImagem = 5 << 20
+	if v, err := strconv.ParseInt(os.Getenv("TAMANHO_MAXIMO_IMAGEM"), 10, 64); err == nil && v > 0 {
+		TamanhoMaximoImagem = v
+	}
+
+	MaximoImagensPorPublicacao = 4
+	if v, err := strconv.Atoi(os.Getenv("MAXIMO_IMAGENS_POR_PUBLICACAO")); err == nil && v > 0 {
+		MaximoImagensPorPublicacao = v
+	}
 }
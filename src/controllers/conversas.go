@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CriarConversa inicia uma conversa com um ou mais usuários, reaproveitando a conversa direta se ela já existir
+func CriarConversa(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var novaConversa models.NovaConversa
+	if erro = json.Unmarshal(corpoRequisicao, &novaConversa); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = novaConversa.Preparar(usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorioUsuarios := repository.NovoRepositorioDeUsuarios(db)
+	for _, participanteID := range novaConversa.Participantes {
+		participante, erro := repositorioUsuarios.BuscarPorId(participanteID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if participante.ID == 0 {
+			respostas.Erro(w, http.StatusNotFound, errors.New("Um dos participantes não existe."))
+			return
+		}
+
+		bloqueado, erro := repositorioUsuarios.ExisteBloqueio(participanteID, usuarioID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		// Perfis privados só recebem mensagens de seguidores aprovados
+		podeVer, erro := repositorioUsuarios.PodeVerPublicacoes(participanteID, usuarioID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if bloqueado || !podeVer {
+			respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível iniciar uma conversa com este usuário."))
+			return
+		}
+	}
+
+	repositorio := repository.NovoRepositorioDeConversas(db)
+
+	var conversaID uint64
+	if len(novaConversa.Participantes) == 1 {
+		conversaID, erro = repositorio.BuscarConversaDireta(usuarioID, novaConversa.Participantes[0])
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
+	statusCode := http.StatusOK
+	if conversaID == 0 {
+		conversaID, erro = repositorio.Criar(append([]uint64{usuarioID}, novaConversa.Participantes...))
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+		statusCode = http.StatusCreated
+	}
+
+	participantes, erro := repositorio.BuscarParticipantes(conversaID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, statusCode, models.Conversa{ID: conversaID, Participantes: participantes})
+}
+
+// BuscarConversas traz as conversas do usuário logado
+func BuscarConversas(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeConversas(db)
+	conversas, erro := repositorio.BuscarPorUsuario(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, conversas)
+}
+
+// EnviarMensagem adiciona uma mensagem a uma conversa da qual o usuário participa
+func EnviarMensagem(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	conversaID, erro := strconv.ParseUint(parametros["conversaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var mensagem models.Mensagem
+	if erro = json.Unmarshal(corpoRequisicao, &mensagem); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = mensagem.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeConversas(db)
+	participa, erro := repositorio.Participa(conversaID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !participa {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Conversa não encontrada."))
+		return
+	}
+
+	bloqueado, erro := repositorio.ExisteBloqueio(conversaID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if bloqueado {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível enviar mensagens nesta conversa."))
+		return
+	}
+
+	mensagem.ConversaID = conversaID
+	mensagem.RemetenteID = usuarioID
+
+	mensagem, erro = repositorio.CriarMensagem(mensagem)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, mensagem)
+}
+
+// BuscarMensagens traz as mensagens de uma conversa paginadas por ?antesDe={mensagemId}&limite=
+func BuscarMensagens(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	conversaID, erro := strconv.ParseUint(parametros["conversaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	antesDe, erro := lerParametroUint(r, "antesDe", 0)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 50, 100)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeConversas(db)
+	participa, erro := repositorio.Participa(conversaID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !participa {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Conversa não encontrada."))
+		return
+	}
+
+	mensagens, erro := repositorio.BuscarMensagens(conversaID, antesDe, limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, mensagens)
+}
+
+// MarcarConversaComoLida marca as mensagens da conversa como lidas pelo usuário logado
+func MarcarConversaComoLida(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	conversaID, erro := strconv.ParseUint(parametros["conversaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeConversas(db)
+	participa, erro := repositorio.Participa(conversaID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !participa {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Conversa não encontrada."))
+		return
+	}
+
+	if erro = repositorio.MarcarComoLida(conversaID, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
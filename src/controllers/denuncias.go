@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// DenunciarPublicacao envia uma publicação para a fila de moderação
+func DenunciarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	denuncia, erro := lerDenuncia(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	publicacao, erro := repository.NovoRepositorioDePublicacoes(db).BuscarPorID(publicacaoID, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacao.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Publicação não encontrada."))
+		return
+	}
+
+	if publicacao.AutorID == usuarioID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível denunciar a sua própria publicação."))
+		return
+	}
+
+	denuncia.DenuncianteID = usuarioID
+	denuncia.UsuarioDenunciadoID = publicacao.AutorID
+	denuncia.PublicacaoID = publicacaoID
+
+	repositorio := repository.NovoRepositorioDeDenuncias(db)
+	denuncia.ID, erro = repositorio.Criar(denuncia)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, denuncia)
+}
+
+// DenunciarUsuario envia um usuário para a fila de moderação
+func DenunciarUsuario(w http.ResponseWriter, r *http.Request) {
+	denuncianteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioID == denuncianteID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível denunciar você mesmo."))
+		return
+	}
+
+	denuncia, erro := lerDenuncia(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	usuario, erro := repository.NovoRepositorioDeUsuarios(db).BuscarPorId(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if usuario.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Usuário não encontrado."))
+		return
+	}
+
+	denuncia.DenuncianteID = denuncianteID
+	denuncia.UsuarioDenunciadoID = usuarioID
+
+	repositorio := repository.NovoRepositorioDeDenuncias(db)
+	denuncia.ID, erro = repositorio.Criar(denuncia)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, denuncia)
+}
+
+// BuscarDenuncias traz a fila de moderação, filtrando pela situação (?status=aberta por padrão)
+func BuscarDenuncias(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.StatusDenunciaAberta
+	}
+	if status == "todas" {
+		status = ""
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDenuncias(db)
+	denuncias, erro := repositorio.Buscar(status)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, denuncias)
+}
+
+// AssumirDenuncia coloca uma denúncia aberta em análise pelo moderador que fez a requisição
+func AssumirDenuncia(w http.ResponseWriter, r *http.Request) {
+	moderadorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	denunciaID, erro := strconv.ParseUint(parametros["denunciaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDenuncias(db)
+	assumida, erro := repositorio.Assumir(denunciaID, moderadorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !assumida {
+		respostas.Erro(w, http.StatusConflict, errors.New("A denúncia não existe ou não está mais aberta."))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// ResolverDenuncia encerra uma denúncia, removendo a publicação ou suspendendo o autor quando for o caso
+func ResolverDenuncia(w http.ResponseWriter, r *http.Request) {
+	moderadorID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	denunciaID, erro := strconv.ParseUint(parametros["denunciaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var resolucao models.ResolucaoDenuncia
+	if erro = json.Unmarshal(corpoRequisicao, &resolucao); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = resolucao.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDenuncias(db)
+	denuncia, erro := repositorio.BuscarPorID(denunciaID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if denuncia.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Denúncia não encontrada."))
+		return
+	}
+
+	if denuncia.Status == models.StatusDenunciaResolvida {
+		respostas.Erro(w, http.StatusConflict, errors.New("A denúncia já foi resolvida."))
+		return
+	}
+
+	if denuncia.Status == models.StatusDenunciaEmAnalise && denuncia.ModeradorID != moderadorID &&
+		!autenticacao.PossuiPapel(r, autenticacao.PapelAdmin) {
+		respostas.Erro(w, http.StatusConflict, errors.New("A denúncia está em análise por outro moderador."))
+		return
+	}
+
+	if resolucao.Resolucao == models.ResolucaoPublicacaoRemovida && denuncia.PublicacaoID == 0 {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("A denúncia não se refere a uma publicação."))
+		return
+	}
+
+	resolvida, erro := repositorio.Resolver(denuncia, moderadorID, resolucao)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !resolvida {
+		respostas.Erro(w, http.StatusConflict, errors.New("A denúncia já foi resolvida."))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarHistoricoDenuncia traz a auditoria das ações de moderação de uma denúncia
+func BuscarHistoricoDenuncia(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	denunciaID, erro := strconv.ParseUint(parametros["denunciaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDenuncias(db)
+	historico, erro := repositorio.BuscarHistorico(denunciaID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, historico)
+}
+
+// lerDenuncia lê e valida o corpo de uma requisição de denúncia
+func lerDenuncia(r *http.Request) (models.Denuncia, error) {
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		return models.Denuncia{}, erro
+	}
+
+	var denuncia models.Denuncia
+	if erro = json.Unmarshal(corpoRequisicao, &denuncia); erro != nil {
+		return models.Denuncia{}, erro
+	}
+
+	if erro = denuncia.Preparar(); erro != nil {
+		return models.Denuncia{}, erro
+	}
+
+	return models.Denuncia{Motivo: denuncia.Motivo, Descricao: denuncia.Descricao}, nil
+}
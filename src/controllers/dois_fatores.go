@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/config"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"api/src/seguranca"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const quantidadeCodigosDeRecuperacao = 10
+
+// CadastrarDoisFatores gera um segredo TOTP para o usuário e retorna a URI otpauth para o app autenticador
+func CadastrarDoisFatores(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	if usuarioID != usuarioIDNoToken {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível configurar dois fatores de um usuário que não seja o seu."))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDoisFatores(db)
+	doisFatoresSalvo, erro := repositorio.Buscar(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if doisFatoresSalvo.Ativo {
+		respostas.Erro(w, http.StatusConflict, errors.New("A autenticação em dois fatores já está ativa para este usuário."))
+		return
+	}
+
+	usuario, erro := repository.NovoRepositorioDeUsuarios(db).BuscarPorId(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	segredo, erro := seguranca.GerarSegredoTOTP()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if erro = repositorio.Cadastrar(usuarioID, segredo); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusCreated, models.DoisFatores{
+		Segredo: segredo,
+		URI:     seguranca.URITOTP(segredo, usuario.Email, config.EmissorTOTP),
+	})
+}
+
+// ConfirmarDoisFatores ativa os dois fatores com um código do app autenticador e retorna os códigos de recuperação
+func ConfirmarDoisFatores(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	if usuarioID != usuarioIDNoToken {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível configurar dois fatores de um usuário que não seja o seu."))
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var codigo models.CodigoDoisFatores
+	if erro = json.Unmarshal(corpoRequisicao, &codigo); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDoisFatores(db)
+	doisFatores, erro := repositorio.Buscar(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if doisFatores.Segredo == "" {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Nenhum cadastro de dois fatores pendente para este usuário."))
+		return
+	}
+
+	if doisFatores.Ativo {
+		respostas.Erro(w, http.StatusConflict, errors.New("A autenticação em dois fatores já está ativa para este usuário."))
+		return
+	}
+
+	passo, valido := seguranca.ValidarCodigoTOTP(doisFatores.Segredo, codigo.Codigo, doisFatores.UltimoPasso)
+	if !valido {
+		respostas.Erro(w, http.StatusUnauthorized, errors.New("Código de verificação inválido."))
+		return
+	}
+
+	codigosDeRecuperacao, erro := seguranca.GerarCodigosDeRecuperacao(quantidadeCodigosDeRecuperacao)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	codigosComHash := make([]string, len(codigosDeRecuperacao))
+	for i, codigoDeRecuperacao := range codigosDeRecuperacao {
+		codigosComHash[i] = seguranca.HashToken(codigoDeRecuperacao)
+	}
+
+	if erro = repositorio.Ativar(usuarioID, passo, codigosComHash); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, struct {
+		CodigosDeRecuperacao []string `json:"codigosDeRecuperacao"`
+	}{
+		CodigosDeRecuperacao: codigosDeRecuperacao,
+	})
+}
+
+// DesativarDoisFatores remove os dois fatores do usuário após conferir um código válido
+func DesativarDoisFatores(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	if usuarioID != usuarioIDNoToken {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível configurar dois fatores de um usuário que não seja o seu."))
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var codigo models.CodigoDoisFatores
+	if erro = json.Unmarshal(corpoRequisicao, &codigo); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDoisFatores(db)
+	doisFatores, erro := repositorio.Buscar(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !doisFatores.Ativo {
+		respostas.Erro(w, http.StatusNotFound, errors.New("A autenticação em dois fatores não está ativa para este usuário."))
+		return
+	}
+
+	valido, erro := verificarSegundoFator(repositorio, doisFatores, codigo.Codigo)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !valido {
+		respostas.Erro(w, http.StatusUnauthorized, errors.New("Código de verificação inválido."))
+		return
+	}
+
+	if erro = repositorio.Desativar(usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// LoginDoisFatores troca o token de MFA pendente e um código válido pelo token de acesso
+func LoginDoisFatores(w http.ResponseWriter, r *http.Request) {
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var login models.LoginDoisFatores
+	if erro = json.Unmarshal(corpoRequisicao, &login); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioID, versao, erro := autenticacao.ValidarTokenMFAPendente(login.Token)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeDoisFatores(db)
+	doisFatores, erro := repositorio.Buscar(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	// Um token emitido antes de um bloqueio não vale mais, mesmo que ainda não tenha expirado
+	if !doisFatores.Ativo || versao != doisFatores.VersaoToken {
+		respostas.Erro(w, http.StatusUnauthorized, errors.New("Token inválido!"))
+		return
+	}
+
+	if doisFatores.Bloqueado {
+		respostas.Erro(w, http.StatusTooManyRequests, errors.New("Muitos códigos inválidos. Tente novamente mais tarde."))
+		return
+	}
+
+	valido, erro := verificarSegundoFator(repositorio, doisFatores, login.Codigo)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !valido {
+		bloqueado, erro := repositorio.RegistrarFalha(usuarioID, models.MaximoFalhasDoisFatores)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if bloqueado {
+			respostas.Erro(w, http.StatusTooManyRequests, errors.New("Muitos códigos inválidos. Faça login novamente mais tarde."))
+			return
+		}
+
+		respostas.Erro(w, http.StatusUnauthorized, errors.New("Código de verificação inválido."))
+		return
+	}
+
+	if erro = repositorio.ZerarFalhas(usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	usuario, erro := repository.NovoRepositorioDeUsuarios(db).BuscarSessao(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	// A conta pode ter sido suspensa entre a senha e o segundo fator
+	if usuario.Suspenso {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Esta conta está suspensa."))
+		return
+	}
+
+	token, erro := autenticacao.CriarToken(usuarioID, usuario.VersaoSessao)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	w.Write([]byte(token))
+}
+
+// verificarSegundoFator aceita um código TOTP ainda não usado ou um código de recuperação, consumindo-o
+func verificarSegundoFator(repositorio *repository.DoisFatores, doisFatores models.DoisFatores, codigo string) (bool, error) {
+	if passo, valido := seguranca.ValidarCodigoTOTP(doisFatores.Segredo, codigo, doisFatores.UltimoPasso); valido {
+		return repositorio.RegistrarPasso(doisFatores.UsuarioID, passo)
+	}
+
+	codigo = strings.ToLower(strings.TrimSpace(codigo))
+	if codigo == "" {
+		return false, nil
+	}
+
+	return repositorio.UsarCodigoDeRecuperacao(doisFatores.UsuarioID, seguranca.HashToken(codigo))
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/config"
+	"api/src/email"
+	"api/src/repository"
+	"api/src/respostas"
+	"api/src/seguranca"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// VerificarEmail confirma o email do usuário a partir do token enviado no cadastro
+func VerificarEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("O token é obrigatório e não pode estar em branco"))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeVerificacoesDeEmail(db)
+	if _, erro = repositorio.Confirmar(seguranca.HashToken(token)); erro != nil {
+		if erro == repository.ErrTokenInvalido {
+			respostas.Erro(w, http.StatusBadRequest, erro)
+			return
+		}
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// ReenviarVerificacaoDeEmail envia um novo token de verificação para o usuário logado
+func ReenviarVerificacaoDeEmail(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	usuario, erro := repositorio.BuscarPorId(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if usuario.EmailVerificado {
+		respostas.Erro(w, http.StatusConflict, errors.New("O email deste usuário já foi verificado"))
+		return
+	}
+
+	if erro = enviarVerificacaoDeEmail(db, usuario.ID, usuario.Email); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// enviarVerificacaoDeEmail gera um novo token de verificação e o envia para o endereço informado
+func enviarVerificacaoDeEmail(db *sql.DB, usuarioID uint64, endereco string) error {
+	token, erro := seguranca.GerarToken()
+	if erro != nil {
+		return erro
+	}
+
+	repositorio := repository.NovoRepositorioDeVerificacoesDeEmail(db)
+	if erro = repositorio.Criar(usuarioID, seguranca.HashToken(token), config.ValidadeVerificacaoEmail); erro != nil {
+		return erro
+	}
+
+	return email.NovoMailer().Enviar(email.Mensagem{
+		Para:    endereco,
+		Assunto: "Confirme o seu email",
+		Corpo: fmt.Sprintf(
+			"Bem-vindo! Para confirmar o seu email, acesse %s/email/verificar?token=%s\n\nO link expira em %s.\n",
+			config.URLAPI, token, config.ValidadeVerificacaoEmail,
+		),
+	})
+}
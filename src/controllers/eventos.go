@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/hub"
+	"api/src/repository"
+	"api/src/respostas"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// intervaloHeartbeat é de quanto em quanto tempo um comentário é enviado para manter a conexão aberta. A cada
+// heartbeat a sessão também é conferida de novo, como o middleware Autenticar faz a cada requisição.
+const intervaloHeartbeat = 25 * time.Second
+
+// Eventos mantém uma conexão Server-Sent Events aberta, enviando novas publicações de quem o usuário segue,
+// novas notificações e novas mensagens diretas assim que acontecem. Se a conta for suspensa ou a sessão for
+// encerrada, a conexão recebe um evento sessao_encerrada e é fechada.
+func Eventos(w http.ResponseWriter, r *http.Request) {
+	usuarioID, versaoSessao, erro := autenticacao.ExtrairSessao(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respostas.Erro(w, http.StatusInternalServerError, errors.New("O servidor não suporta streaming."))
+		return
+	}
+
+	assinatura := hub.Assinar(usuarioID)
+	defer hub.Cancelar(assinatura)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, ": conectado\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(intervaloHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if erro = conferirSessaoAberta(usuarioID, versaoSessao); erro != nil {
+				dados, _ := json.Marshal(struct {
+					Erro string `json:"erro"`
+				}{Erro: erro.Error()})
+				fmt.Fprintf(w, "event: sessao_encerrada\ndata: %s\n\n", dados)
+				flusher.Flush()
+				return
+			}
+			if _, erro = fmt.Fprint(w, ": ping\n\n"); erro != nil {
+				return
+			}
+		case evento := <-assinatura.Eventos:
+			if _, erro = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evento.Tipo, evento.Dados); erro != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// conferirSessaoAberta repete para uma conexão já aberta a verificação de sessão do middleware Autenticar. Uma
+// falha ao consultar o banco não derruba a conexão; ela é conferida de novo no próximo heartbeat.
+func conferirSessaoAberta(usuarioID, versaoSessao uint64) error {
+	db, erro := banco.Compartilhada()
+	if erro != nil {
+		log.Printf("erro ao conferir a sessão da conexão de eventos: %v", erro)
+		return nil
+	}
+
+	usuario, erro := repository.NovoRepositorioDeUsuarios(db).BuscarSessao(usuarioID)
+	if erro != nil {
+		log.Printf("erro ao conferir a sessão da conexão de eventos: %v", erro)
+		return nil
+	}
+
+	_, erro = autenticacao.ConferirSessao(usuario, versaoSessao)
+	return erro
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// janelaMaximaEmAlta é o maior período, em horas, aceito no cálculo das hashtags em alta
+const janelaMaximaEmAlta = 7 * 24
+
+// BuscarPublicacoesPorHashtag traz as publicações com uma hashtag, de forma paginada. A hashtag é comparada
+// sem acentos e sem diferenciar maiúsculas, então /hashtags/Eleição e /hashtags/eleicao trazem o mesmo resultado.
+func BuscarPublicacoesPorHashtag(w http.ResponseWriter, r *http.Request) {
+	hashtag := models.NormalizarHashtag(mux.Vars(r)["hashtag"])
+	if !models.HashtagValida(hashtag) {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("A hashtag informada é inválida."))
+		return
+	}
+
+	solicitanteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 20, 100)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	pagina, erro := lerPagina(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeHashtags(db)
+	publicacoes, erro := repositorio.BuscarPublicacoes(hashtag, solicitanteID, limite, (pagina-1)*limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacoes == nil {
+		publicacoes = []models.Publicacao{}
+	}
+
+	respostas.JSON(w, http.StatusOK, publicacoes)
+}
+
+// BuscarHashtagsEmAlta traz as hashtags mais usadas nas últimas horas (?horas, padrão 24)
+func BuscarHashtagsEmAlta(w http.ResponseWriter, r *http.Request) {
+	horas, erro := lerParametroUint(r, "horas", 24)
+	if erro != nil || horas == 0 || horas > janelaMaximaEmAlta {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("O parâmetro horas deve ficar entre 1 e 168."))
+		return
+	}
+
+	limite, erro := lerLimite(r, 10, 50)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeHashtags(db)
+	hashtags, erro := repositorio.BuscarEmAlta(time.Duration(horas)*time.Hour, limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if hashtags == nil {
+		hashtags = []models.Hashtag{}
+	}
+
+	respostas.JSON(w, http.StatusOK, hashtags)
+}
@@ -8,7 +8,9 @@ import (
 	"api/src/respostas"
 	"api/src/seguranca"
 	"encoding/json"
+	"errors"
 	"io"
+	"log"
 	"net/http"
 )
 
@@ -45,7 +47,45 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, erro := autenticacao.CriarToken(usuarioSalvoNoBanco.ID)
+	if usuarioSalvoNoBanco.Suspenso {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Esta conta está suspensa."))
+		return
+	}
+
+	// Senhas salvas com um algoritmo ou custo antigo são atualizadas aproveitando a senha em texto puro
+	if seguranca.PrecisaRehash(usuarioSalvoNoBanco.Senha) {
+		if senhaComHash, erro := seguranca.Hash(usuario.Senha); erro != nil {
+			log.Printf("erro ao atualizar o hash da senha: %v", erro)
+		} else if erro = repositorio.AtualizarSenha(usuarioSalvoNoBanco.ID, string(senhaComHash)); erro != nil {
+			log.Printf("erro ao atualizar o hash da senha: %v", erro)
+		}
+	}
+
+	doisFatores, erro := repository.NovoRepositorioDeDoisFatores(db).Buscar(usuarioSalvoNoBanco.ID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	// Com dois fatores ativos, o token de acesso só é emitido em /login/2fa
+	if doisFatores.Ativo {
+		tokenMFAPendente, erro := autenticacao.CriarTokenMFAPendente(usuarioSalvoNoBanco.ID, doisFatores.VersaoToken)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		respostas.JSON(w, http.StatusAccepted, struct {
+			MFAPendente bool   `json:"mfaPendente"`
+			Token       string `json:"token"`
+		}{
+			MFAPendente: true,
+			Token:       tokenMFAPendente,
+		})
+		return
+	}
+
+	token, erro := autenticacao.CriarToken(usuarioSalvoNoBanco.ID, usuarioSalvoNoBanco.VersaoSessao)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"api/src/armazenamento"
+	"api/src/config"
+	"api/src/imagens"
+	"api/src/models"
+	"api/src/seguranca"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// memoriaMultipart é quanto do formulário fica em memória antes de ir para arquivos temporários
+const memoriaMultipart = 8 << 20
+
+// ehMultipart informa se a requisição foi enviada como multipart/form-data
+func ehMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// lerPublicacaoMultipart lê os campos titulo e conteudo e as imagens (campo imagens) de um formulário multipart,
+// retornando o status HTTP adequado quando algo estiver errado
+func lerPublicacaoMultipart(w http.ResponseWriter, r *http.Request) (models.Publicacao, []imagens.Resultado, int, error) {
+	limite := int64(config.MaximoImagensPorPublicacao)*config.TamanhoMaximoImagem + 1<<20
+	r.Body = http.MaxBytesReader(w, r.Body, limite)
+
+	if erro := r.ParseMultipartForm(memoriaMultipart); erro != nil {
+		var muitoGrande *http.MaxBytesError
+		if errors.As(erro, &muitoGrande) {
+			return models.Publicacao{}, nil, http.StatusRequestEntityTooLarge, errors.New("A requisição passa do tamanho máximo permitido.")
+		}
+		return models.Publicacao{}, nil, http.StatusBadRequest, erro
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	publicacao := models.Publicacao{
+		Titulo:   r.FormValue("titulo"),
+		Conteudo: r.FormValue("conteudo"),
+	}
+
+	if citadaID := r.FormValue("citadaId"); citadaID != "" {
+		var erro error
+		if publicacao.CitadaID, erro = strconv.ParseUint(citadaID, 10, 64); erro != nil {
+			return models.Publicacao{}, nil, http.StatusBadRequest, errors.New("O campo citadaId deve ser o ID de uma publicação.")
+		}
+	}
+
+	arquivos := r.MultipartForm.File["imagens"]
+	if len(arquivos) > config.MaximoImagensPorPublicacao {
+		return models.Publicacao{}, nil, http.StatusBadRequest,
+			fmt.Errorf("Uma publicação pode ter no máximo %d imagens.", config.MaximoImagensPorPublicacao)
+	}
+
+	var enviadas []imagens.Resultado
+	for _, arquivo := range arquivos {
+		processada, status, erro := lerImagem(arquivo, imagens.TamanhosPublicacao)
+		if erro != nil {
+			return models.Publicacao{}, nil, status, erro
+		}
+		enviadas = append(enviadas, processada)
+	}
+
+	return publicacao, enviadas, http.StatusOK, nil
+}
+
+// lerImagemMultipart lê a imagem única (campo imagem) de um formulário multipart, usado no avatar e no banner
+func lerImagemMultipart(w http.ResponseWriter, r *http.Request, tamanhos []imagens.Tamanho) (imagens.Resultado, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.TamanhoMaximoImagem+1<<20)
+
+	if erro := r.ParseMultipartForm(memoriaMultipart); erro != nil {
+		var muitoGrande *http.MaxBytesError
+		if errors.As(erro, &muitoGrande) {
+			return imagens.Resultado{}, http.StatusRequestEntityTooLarge, errors.New("A requisição passa do tamanho máximo permitido.")
+		}
+		return imagens.Resultado{}, http.StatusBadRequest, erro
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	arquivos := r.MultipartForm.File["imagem"]
+	if len(arquivos) != 1 {
+		return imagens.Resultado{}, http.StatusBadRequest, errors.New("Envie exatamente uma imagem no campo imagem.")
+	}
+
+	return lerImagem(arquivos[0], tamanhos)
+}
+
+// lerImagem valida um arquivo enviado e o processa, retornando o status HTTP adequado quando algo estiver errado
+func lerImagem(arquivo *multipart.FileHeader, tamanhos []imagens.Tamanho) (imagens.Resultado, int, error) {
+	if arquivo.Size > config.TamanhoMaximoImagem {
+		return imagens.Resultado{}, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("A imagem %s passa do tamanho máximo de %d bytes.", arquivo.Filename, config.TamanhoMaximoImagem)
+	}
+
+	aberto, erro := arquivo.Open()
+	if erro != nil {
+		return imagens.Resultado{}, http.StatusBadRequest, erro
+	}
+	conteudo, erro := io.ReadAll(io.LimitReader(aberto, config.TamanhoMaximoImagem+1))
+	aberto.Close()
+	if erro != nil {
+		return imagens.Resultado{}, http.StatusBadRequest, erro
+	}
+
+	if int64(len(conteudo)) > config.TamanhoMaximoImagem {
+		return imagens.Resultado{}, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("A imagem %s passa do tamanho máximo de %d bytes.", arquivo.Filename, config.TamanhoMaximoImagem)
+	}
+
+	// O tipo é descoberto pelo conteúdo do arquivo, e não pelo nome ou pelo Content-Type enviado pelo cliente
+	tipo := http.DetectContentType(conteudo)
+	if _, aceito := models.TiposDeImagemAceitos[tipo]; !aceito {
+		return imagens.Resultado{}, http.StatusUnsupportedMediaType,
+			fmt.Errorf("O arquivo %s não é uma imagem JPEG, PNG, GIF ou WebP.", arquivo.Filename)
+	}
+
+	// A imagem nunca é guardada como foi enviada: ela é recodificada sem metadados e ganha as versões reduzidas
+	processada, erro := imagens.Processar(conteudo, tamanhos)
+	if errors.Is(erro, imagens.ErrImagemInvalida) {
+		return imagens.Resultado{}, http.StatusUnsupportedMediaType,
+			fmt.Errorf("O arquivo %s não pôde ser lido como imagem.", arquivo.Filename)
+	}
+	if errors.Is(erro, imagens.ErrImagemGrandeDemais) {
+		return imagens.Resultado{}, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("A imagem %s tem dimensões grandes demais.", arquivo.Filename)
+	}
+	if erro != nil {
+		return imagens.Resultado{}, http.StatusInternalServerError, erro
+	}
+
+	return processada, http.StatusOK, nil
+}
+
+// salvarImagens guarda as imagens e as suas versões reduzidas no armazenamento, dentro do prefixo informado,
+// e retorna as mídias correspondentes. Se uma delas falhar, as que já foram guardadas são removidas.
+func salvarImagens(prefixo string, enviadas []imagens.Resultado) ([]models.Midia, error) {
+	var midias []models.Midia
+	for _, imagem := range enviadas {
+		midia, erro := salvarImagem(prefixo, imagem)
+		if erro != nil {
+			removerMidias(midias)
+			return nil, erro
+		}
+		midias = append(midias, midia)
+	}
+
+	return midias, nil
+}
+
+// salvarImagem guarda o original e as versões reduzidas de uma imagem com o mesmo token no nome
+func salvarImagem(prefixo string, imagem imagens.Resultado) (models.Midia, error) {
+	destino := armazenamento.Novo()
+
+	token, erro := seguranca.GerarToken()
+	if erro != nil {
+		return models.Midia{}, erro
+	}
+
+	chave := prefixo + "/" + token + models.TiposDeImagemAceitos[imagem.Original.Tipo]
+	if erro = destino.Salvar(chave, imagem.Original.Conteudo, imagem.Original.Tipo); erro != nil {
+		return models.Midia{}, erro
+	}
+
+	midia := models.Midia{
+		Chave:    chave,
+		URL:      destino.URL(chave),
+		Tipo:     imagem.Original.Tipo,
+		Tamanho:  int64(len(imagem.Original.Conteudo)),
+		Largura:  imagem.Original.Largura,
+		Altura:   imagem.Original.Altura,
+		Blurhash: imagem.Blurhash,
+	}
+
+	for _, versao := range imagem.Versoes {
+		chave := prefixo + "/" + token + "_" + versao.Nome + models.TiposDeImagemAceitos[versao.Tipo]
+		if erro = destino.Salvar(chave, versao.Conteudo, versao.Tipo); erro != nil {
+			removerMidias([]models.Midia{midia})
+			return models.Midia{}, erro
+		}
+
+		midia.Variantes = append(midia.Variantes, models.VarianteMidia{
+			Nome:    versao.Nome,
+			URL:     destino.URL(chave),
+			Largura: versao.Largura,
+			Altura:  versao.Altura,
+			Chave:   chave,
+		})
+	}
+
+	return midia, nil
+}
+
+// removerMidias apaga do armazenamento arquivos que não chegaram a ser ligados a uma publicação
+func removerMidias(midias []models.Midia) {
+	destino := armazenamento.Novo()
+	for _, midia := range midias {
+		for _, chave := range midia.Chaves() {
+			if erro := destino.Remover(chave); erro != nil {
+				log.Printf("erro ao remover mídia %s: %v", chave, erro)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// BuscarNotificacoes traz as notificações do usuário logado, de forma paginada, junto com o total de não lidas
+func BuscarNotificacoes(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 20, 100)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	pagina, erro := lerPagina(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeNotificacoes(db)
+	notificacoes, erro := repositorio.Buscar(usuarioID, limite, (pagina-1)*limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	naoLidas, erro := repositorio.ContarNaoLidas(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if notificacoes == nil {
+		notificacoes = []models.Notificacao{}
+	}
+
+	respostas.JSON(w, http.StatusOK, models.PaginaNotificacoes{
+		NaoLidas:     naoLidas,
+		Notificacoes: notificacoes,
+	})
+}
+
+// MarcarNotificacaoComoLida marca uma notificação do usuário logado como lida
+func MarcarNotificacaoComoLida(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	notificacaoID, erro := strconv.ParseUint(parametros["notificacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeNotificacoes(db)
+	encontrada, erro := repositorio.MarcarComoLida(usuarioID, notificacaoID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !encontrada {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Notificação não encontrada."))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// MarcarTodasNotificacoesComoLidas marca todas as notificações do usuário logado como lidas
+func MarcarTodasNotificacoesComoLidas(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeNotificacoes(db)
+	if erro = repositorio.MarcarTodasComoLidas(usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// lerParametroUint lê um parâmetro numérico opcional da query string, retornando o padrão quando ele não é informado
+func lerParametroUint(r *http.Request, nome string, padrao uint64) (uint64, error) {
+	valor := r.URL.Query().Get(nome)
+	if valor == "" {
+		return padrao, nil
+	}
+
+	numero, erro := strconv.ParseUint(valor, 10, 64)
+	if erro != nil {
+		return 0, fmt.Errorf("o parâmetro %s deve ser um número inteiro positivo", nome)
+	}
+
+	return numero, nil
+}
+
+// lerLimite lê o parâmetro ?limite, garantindo que ele fique entre 1 e o máximo permitido
+func lerLimite(r *http.Request, padrao, maximo uint64) (uint64, error) {
+	limite, erro := lerParametroUint(r, "limite", padrao)
+	if erro != nil {
+		return 0, erro
+	}
+
+	if limite == 0 {
+		limite = padrao
+	}
+	if limite > maximo {
+		limite = maximo
+	}
+
+	return limite, nil
+}
+
+// maximoPagina limita o parâmetro ?pagina. Sem ele, uma página enorme estoura o cálculo do deslocamento
+// ((pagina-1)*limite) e chega ao banco como um OFFSET negativo ou sem sentido.
+const maximoPagina = 10000
+
+// lerPagina lê o parâmetro ?pagina, que começa em 1, limitando-o a maximoPagina
+func lerPagina(r *http.Request) (uint64, error) {
+	pagina, erro := lerParametroUint(r, "pagina", 1)
+	if erro != nil || pagina == 0 {
+		return 0, errors.New("O parâmetro pagina deve ser um número positivo.")
+	}
+
+	if pagina > maximoPagina {
+		pagina = maximoPagina
+	}
+
+	return pagina, nil
+}
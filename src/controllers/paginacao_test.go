@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLerPagina(t *testing.T) {
+	casos := []struct {
+		nome     string
+		consulta string
+		esperado uint64
+		erro     bool
+	}{
+		{"sem parâmetro", "", 1, false},
+		{"página informada", "?pagina=3", 3, false},
+		{"no limite", "?pagina=10000", maximoPagina, false},
+		{"acima do limite", "?pagina=10001", maximoPagina, false},
+		{"estouraria o deslocamento", "?pagina=18446744073709551615", maximoPagina, false},
+		{"zero", "?pagina=0", 0, true},
+		{"negativa", "?pagina=-1", 0, true},
+		{"não numérica", "?pagina=abc", 0, true},
+		{"maior que uint64", "?pagina=18446744073709551616", 0, true},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			pagina, erro := lerPagina(httptest.NewRequest("GET", "/"+caso.consulta, nil))
+			if (erro != nil) != caso.erro {
+				t.Fatalf("erro = %v, esperado erro: %v", erro, caso.erro)
+			}
+			if pagina != caso.esperado {
+				t.Errorf("pagina = %d, esperado %d", pagina, caso.esperado)
+			}
+		})
+	}
+}
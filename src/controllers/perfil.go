@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/imagens"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AtualizarAvatar troca a imagem de avatar do usuário pela enviada no campo imagem
+func AtualizarAvatar(w http.ResponseWriter, r *http.Request) {
+	atualizarImagemPerfil(w, r, models.ImagemPerfilAvatar, imagens.TamanhosAvatar)
+}
+
+// RemoverAvatar tira a imagem de avatar do usuário
+func RemoverAvatar(w http.ResponseWriter, r *http.Request) {
+	atualizarImagemPerfil(w, r, models.ImagemPerfilAvatar, nil)
+}
+
+// AtualizarBanner troca a imagem de banner do usuário pela enviada no campo imagem
+func AtualizarBanner(w http.ResponseWriter, r *http.Request) {
+	atualizarImagemPerfil(w, r, models.ImagemPerfilBanner, imagens.TamanhosBanner)
+}
+
+// RemoverBanner tira a imagem de banner do usuário
+func RemoverBanner(w http.ResponseWriter, r *http.Request) {
+	atualizarImagemPerfil(w, r, models.ImagemPerfilBanner, nil)
+}
+
+// atualizarImagemPerfil grava a nova imagem de perfil, ou remove a atual quando não há tamanhos (DELETE),
+// e responde com o usuário atualizado
+func atualizarImagemPerfil(w http.ResponseWriter, r *http.Request, imagem string, tamanhos []imagens.Tamanho) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	if usuarioID != usuarioIDNoToken && !autenticacao.PossuiPapel(r, autenticacao.PapelAdmin) {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível atualizar um usuário que não seja o seu."))
+		return
+	}
+
+	var midia *models.Midia
+	if tamanhos != nil {
+		if !ehMultipart(r) {
+			respostas.Erro(w, http.StatusUnsupportedMediaType, errors.New("Envie a imagem como multipart/form-data."))
+			return
+		}
+
+		enviada, status, erro := lerImagemMultipart(w, r, tamanhos)
+		if erro != nil {
+			respostas.Erro(w, status, erro)
+			return
+		}
+
+		salva, erro := salvarImagem(imagem+"s", enviada)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+		midia = &salva
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	encontrado, erro := repositorio.AtualizarImagemPerfil(usuarioID, imagem, midia)
+	if erro != nil || !encontrado {
+		if midia != nil {
+			removerMidias([]models.Midia{*midia})
+		}
+	}
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	if !encontrado {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Usuário não encontrado."))
+		return
+	}
+
+	usuario, erro := repositorio.BuscarPorId(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, usuario)
+}
+
+// BuscarSugestoes recomenda contas para o usuário logado seguir (?limite, padrão 10)
+func BuscarSugestoes(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 10, 50)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	sugestoes, erro := repositorio.BuscarSugestoes(usuarioID, limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if sugestoes == nil {
+		sugestoes = []models.Sugestao{}
+	}
+
+	respostas.JSON(w, http.StatusOK, sugestoes)
+}
@@ -3,14 +3,19 @@ package controllers
 import (
 	"api/src/autenticacao"
 	"api/src/banco"
+	"api/src/config"
+	"api/src/imagens"
 	"api/src/models"
 	"api/src/repository"
 	"api/src/respostas"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
 )
@@ -23,16 +28,30 @@ func CriarPublicacao(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	corpoRequisicao, erro := io.ReadAll(r.Body)
-	if erro != nil {
-		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
-		return
-	}
+	var (
+		publicacao models.Publicacao
+		enviadas   []imagens.Resultado
+	)
 
-	var publicacao models.Publicacao
-	if erro = json.Unmarshal(corpoRequisicao, &publicacao); erro != nil {
-		respostas.Erro(w, http.StatusBadRequest, erro)
-		return
+	// Publicações com imagens chegam como multipart/form-data; as só de texto continuam aceitando JSON
+	if ehMultipart(r) {
+		var status int
+		publicacao, enviadas, status, erro = lerPublicacaoMultipart(w, r)
+		if erro != nil {
+			respostas.Erro(w, status, erro)
+			return
+		}
+	} else {
+		corpoRequisicao, erro := io.ReadAll(r.Body)
+		if erro != nil {
+			respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+			return
+		}
+
+		if erro = json.Unmarshal(corpoRequisicao, &publicacao); erro != nil {
+			respostas.Erro(w, http.StatusBadRequest, erro)
+			return
+		}
 	}
 
 	publicacao.AutorID = usuarioID
@@ -49,8 +68,41 @@ func CriarPublicacao(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	if config.ExigirEmailVerificado {
+		verificado, erro := repository.NovoRepositorioDeUsuarios(db).EmailVerificado(usuarioID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if !verificado {
+			respostas.Erro(w, http.StatusForbidden, errors.New("Confirme o seu email antes de publicar."))
+			return
+		}
+	}
+
+	if publicacao.CitadaID != 0 {
+		if status, erro := verificarCompartilhamento(db, publicacao.CitadaID, usuarioID); erro != nil {
+			respostas.Erro(w, status, erro)
+			return
+		}
+	}
+
+	publicacao.Midias, erro = salvarImagens("publicacoes", enviadas)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
 	repositorio := repository.NovoRepositorioDePublicacoes(db)
 	publicacao.ID, erro = repositorio.Criar(publicacao)
+	if erro != nil {
+		removerMidias(publicacao.Midias)
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	publicacao, erro = repositorio.BuscarPorID(publicacao.ID, usuarioID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
@@ -86,6 +138,12 @@ func BuscarPublicacoes(w http.ResponseWriter, r *http.Request) {
 
 // BuscarPublicacao traz uma única publicação
 func BuscarPublicacao(w http.ResponseWriter, r *http.Request) {
+	solicitanteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
@@ -101,12 +159,30 @@ func BuscarPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDePublicacoes(db)
-	publicacao, erro := repositorio.BuscarPorID(publicacaoID)
+	publicacao, erro := repositorio.BuscarPorID(publicacaoID, solicitanteID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	repositorioUsuarios := repository.NovoRepositorioDeUsuarios(db)
+	bloqueado, erro := repositorioUsuarios.ExisteBloqueio(publicacao.AutorID, solicitanteID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
+	podeVer, erro := repositorioUsuarios.PodeVerPublicacoes(publicacao.AutorID, solicitanteID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if bloqueado || !podeVer {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Publicação não encontrada."))
+		return
+	}
+
 	respostas.JSON(w, http.StatusOK, publicacao)
 }
 
@@ -133,13 +209,13 @@ func AtualizarPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDePublicacoes(db)
-	publicacaoSalvaNoBanco, erro := repositorio.BuscarPorID(publicacaoID)
+	publicacaoSalvaNoBanco, erro := repositorio.BuscarPorID(publicacaoID, usuarioID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
-	if publicacaoSalvaNoBanco.AutorID != usuarioID {
+	if publicacaoSalvaNoBanco.AutorID != usuarioID && !autenticacao.PossuiPapel(r, autenticacao.PapelAdmin) {
 		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível atualizar uma publicao que não seja sua."))
 		return
 	}
@@ -192,13 +268,13 @@ func DeletarPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDePublicacoes(db)
-	publicacaoSalvaNoBanco, erro := repositorio.BuscarPorID(publicacaoID)
+	publicacaoSalvaNoBanco, erro := repositorio.BuscarPorID(publicacaoID, usuarioID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
-	if publicacaoSalvaNoBanco.AutorID != usuarioID {
+	if publicacaoSalvaNoBanco.AutorID != usuarioID && !autenticacao.PossuiPapel(r, autenticacao.PapelAdmin) {
 		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível deletar uma publicao que não seja sua."))
 		return
 	}
@@ -213,6 +289,12 @@ func DeletarPublicacao(w http.ResponseWriter, r *http.Request) {
 
 // BuscarPublicacoesPorUsuario traz as publicações de um usuário específico
 func BuscarPublicacoesPorUsuario(w http.ResponseWriter, r *http.Request) {
+	solicitanteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
 	if erro != nil {
@@ -227,8 +309,19 @@ func BuscarPublicacoesPorUsuario(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	podeVer, erro := repository.NovoRepositorioDeUsuarios(db).PodeVerPublicacoes(usuarioID, solicitanteID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !podeVer {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Esta conta é privada."))
+		return
+	}
+
 	repositorio := repository.NovoRepositorioDePublicacoes(db)
-	publicacoes, erro := repositorio.BuscarPorUsuario(usuarioID)
+	publicacoes, erro := repositorio.BuscarPorUsuario(usuarioID, solicitanteID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
@@ -239,6 +332,12 @@ func BuscarPublicacoesPorUsuario(w http.ResponseWriter, r *http.Request) {
 
 // CurtirPublicacao adiciona uma curtida a uma publicação
 func CurtirPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 	puclicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
@@ -254,7 +353,7 @@ func CurtirPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDePublicacoes(db)
-	if erro = repositorio.Curtir(puclicacaoID); erro != nil {
+	if erro = repositorio.Curtir(puclicacaoID, usuarioID); erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
@@ -263,6 +362,12 @@ func CurtirPublicacao(w http.ResponseWriter, r *http.Request) {
 
 // DescururtirPublicacao subtrai uma curtida a uma publicação
 func DescurtirPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	parametros := mux.Vars(r)
 	puclicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
 	if erro != nil {
@@ -278,9 +383,281 @@ func DescurtirPublicacao(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDePublicacoes(db)
-	if erro = repositorio.Descurtir(puclicacaoID); erro != nil {
+	if erro = repositorio.Descurtir(puclicacaoID, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarMencoes traz, de forma paginada, as publicações que mencionam o usuário logado
+func BuscarMencoes(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 20, 100)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	pagina, erro := lerPagina(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDePublicacoes(db)
+	publicacoes, erro := repositorio.BuscarMencionando(usuarioID, limite, (pagina-1)*limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacoes == nil {
+		publicacoes = []models.Publicacao{}
+	}
+
+	respostas.JSON(w, http.StatusOK, publicacoes)
+}
+
+// PesquisarPublicacoes faz a busca textual nas publicações (?q=), de forma paginada, ordenada por relevância
+// ou pelas mais recentes (?ordem=recentes)
+func PesquisarPublicacoes(w http.ResponseWriter, r *http.Request) {
+	texto := strings.TrimSpace(r.URL.Query().Get("q"))
+	if tamanho := utf8.RuneCountInString(texto); tamanho < 2 || tamanho > 100 {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("O parâmetro q deve ter entre 2 e 100 caracteres."))
+		return
+	}
+
+	ordem := r.URL.Query().Get("ordem")
+	if ordem == "" {
+		ordem = repository.OrdemRelevancia
+	}
+	if ordem != repository.OrdemRelevancia && ordem != repository.OrdemRecentes {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("O parâmetro ordem deve ser relevancia ou recentes."))
+		return
+	}
+
+	solicitanteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 20, 100)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	pagina, erro := lerPagina(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDePublicacoes(db)
+	encontradas, erro := repositorio.Pesquisar(texto, solicitanteID, ordem, limite, (pagina-1)*limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if encontradas == nil {
+		encontradas = []models.PublicacaoEncontrada{}
+	}
+
+	respostas.JSON(w, http.StatusOK, encontradas)
+}
+
+// RepublicarPublicacao compartilha uma publicação com os seguidores do usuário logado
+func RepublicarPublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	if status, erro := verificarCompartilhamento(db, publicacaoID, usuarioID); erro != nil {
+		respostas.Erro(w, status, erro)
+		return
+	}
+
+	repositorio := repository.NovoRepositorioDePublicacoes(db)
+	if erro = repositorio.Republicar(publicacaoID, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DesfazerRepublicacao tira a republicação do usuário logado
+func DesfazerRepublicacao(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDePublicacoes(db)
+	if erro = repositorio.DesfazerRepublicacao(publicacaoID, usuarioID); erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
+
+// verificarCompartilhamento confere se o usuário pode republicar ou citar a publicação. Publicações de contas
+// privadas só podem ser compartilhadas pelo próprio autor, para não chegarem a quem não segue a conta.
+func verificarCompartilhamento(db *sql.DB, publicacaoID, usuarioID uint64) (int, error) {
+	publicacao, erro := repository.NovoRepositorioDePublicacoes(db).BuscarPorID(publicacaoID, usuarioID)
+	if erro != nil {
+		return http.StatusInternalServerError, erro
+	}
+
+	repositorioUsuarios := repository.NovoRepositorioDeUsuarios(db)
+	bloqueado, erro := repositorioUsuarios.ExisteBloqueio(publicacao.AutorID, usuarioID)
+	if erro != nil {
+		return http.StatusInternalServerError, erro
+	}
+
+	podeVer, erro := repositorioUsuarios.PodeVerPublicacoes(publicacao.AutorID, usuarioID)
+	if erro != nil {
+		return http.StatusInternalServerError, erro
+	}
+
+	if publicacao.ID == 0 || bloqueado || !podeVer {
+		return http.StatusNotFound, errors.New("Publicação não encontrada.")
+	}
+
+	if publicacao.AutorID != usuarioID {
+		autor, erro := repositorioUsuarios.BuscarPorId(publicacao.AutorID)
+		if erro != nil {
+			return http.StatusInternalServerError, erro
+		}
+
+		if autor.Privado {
+			return http.StatusForbidden, errors.New("Publicações de contas privadas só podem ser compartilhadas pelo autor.")
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// BuscarCitacoes traz as publicações que citam uma publicação, de forma paginada, das mais novas para as mais antigas
+func BuscarCitacoes(w http.ResponseWriter, r *http.Request) {
+	solicitanteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	publicacaoID, erro := strconv.ParseUint(parametros["publicacaoId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 20, 100)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	pagina, erro := lerPagina(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDePublicacoes(db)
+	publicacao, erro := repositorio.BuscarPorID(publicacaoID, solicitanteID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	repositorioUsuarios := repository.NovoRepositorioDeUsuarios(db)
+	bloqueado, erro := repositorioUsuarios.ExisteBloqueio(publicacao.AutorID, solicitanteID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	podeVer, erro := repositorioUsuarios.PodeVerPublicacoes(publicacao.AutorID, solicitanteID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacao.ID == 0 || bloqueado || !podeVer {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Publicação não encontrada."))
+		return
+	}
+
+	publicacoes, erro := repositorio.BuscarCitacoes(publicacaoID, solicitanteID, limite, (pagina-1)*limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if publicacoes == nil {
+		publicacoes = []models.Publicacao{}
+	}
+
+	respostas.JSON(w, http.StatusOK, publicacoes)
+}
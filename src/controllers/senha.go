@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"api/src/banco"
+	"api/src/config"
+	"api/src/email"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"api/src/seguranca"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// EsquecerSenha envia por email um link para o usuário redefinir a senha
+func EsquecerSenha(w http.ResponseWriter, r *http.Request) {
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var requisicao models.EsqueciSenha
+	if erro = json.Unmarshal(corpoRequisicao, &requisicao); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	requisicao.Email = strings.TrimSpace(requisicao.Email)
+	if requisicao.Email == "" {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("O email é obrigatório e não pode estar em branco"))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorioUsuarios := repository.NovoRepositorioDeUsuarios(db)
+	usuario, erro := repositorioUsuarios.BuscarPorEmail(requisicao.Email)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	// A resposta é a mesma exista ou não o email, para não revelar quem está cadastrado
+	if usuario.ID == 0 {
+		respostas.JSON(w, http.StatusNoContent, nil)
+		return
+	}
+
+	token, erro := seguranca.GerarToken()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	repositorio := repository.NovoRepositorioDeRedefinicoesDeSenha(db)
+	if erro = repositorio.Criar(usuario.ID, seguranca.HashToken(token), config.ValidadeRedefinicaoSenha); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	mensagem := email.Mensagem{
+		Para:    requisicao.Email,
+		Assunto: "Redefinição de senha",
+		Corpo: fmt.Sprintf(
+			"Recebemos um pedido para redefinir a sua senha.\n\nAcesse %s/redefinir-senha?token=%s para escolher uma nova senha.\n\nO link expira em %s. Se não foi você, ignore este email.\n",
+			config.URLFrontend, token, config.ValidadeRedefinicaoSenha,
+		),
+	}
+	if erro = email.NovoMailer().Enviar(mensagem); erro != nil {
+		log.Printf("erro ao enviar email de redefinição de senha: %v", erro)
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// RedefinirSenha troca a senha do usuário usando um token de redefinição válido
+func RedefinirSenha(w http.ResponseWriter, r *http.Request) {
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var redefinicao models.RedefinicaoSenha
+	if erro = json.Unmarshal(corpoRequisicao, &redefinicao); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if redefinicao.Token == "" {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("O token é obrigatório e não pode estar em branco"))
+		return
+	}
+
+	if redefinicao.Nova == "" {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("A nova senha é obrigatória e não pode estar em branco"))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	// O hash vem antes de usar o token, que só é gasto junto com a troca da senha
+	senhaComHash, erro := seguranca.Hash(redefinicao.Nova)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	repositorio := repository.NovoRepositorioDeRedefinicoesDeSenha(db)
+	erro = repositorio.Redefinir(seguranca.HashToken(redefinicao.Token), string(senhaComHash))
+	if erro == repository.ErrTokenInvalido {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
@@ -9,14 +9,20 @@ import (
 	"api/src/seguranca"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
 )
 
+// tamanhoMinimoBuscaUsuarios é o menor termo aceito na busca de usuários; termos menores trariam quase todo mundo
+const tamanhoMinimoBuscaUsuarios = 2
+
 // CriarUsuario insere um usuário no banco de de dados
 func CriarUsuario(w http.ResponseWriter, r *http.Request) {
 	corpoRequest, erro := io.ReadAll(r.Body)
@@ -50,29 +56,63 @@ func CriarUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if erro = enviarVerificacaoDeEmail(db, usuario.ID, usuario.Email); erro != nil {
+		log.Printf("erro ao enviar verificação de email: %v", erro)
+	}
+
+	usuario.Senha = ""
 	respostas.JSON(w, http.StatusCreated, usuario)
 }
 
-// BuscarUsuarios buscar todos os usuários salvos no bando de dados
+// BuscarUsuarios busca usuários pelo nome ou nick (?usuario=), de forma paginada
 func BurscarUsuarios(w http.ResponseWriter, r *http.Request) {
-	nomeOuNick := strings.ToLower(r.URL.Query().Get("usuario"))
+	nomeOuNick := strings.TrimSpace(r.URL.Query().Get("usuario"))
+	if tamanho := utf8.RuneCountInString(nomeOuNick); tamanho < tamanhoMinimoBuscaUsuarios || tamanho > 50 {
+		respostas.Erro(w, http.StatusBadRequest,
+			fmt.Errorf("O parâmetro usuario deve ter entre %d e 50 caracteres.", tamanhoMinimoBuscaUsuarios))
+		return
+	}
+
+	solicitanteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 20, 100)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	pagina, erro := lerPagina(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
 
 	db, erro := banco.Conectar()
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
 	}
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDeUsuarios(db)
-	usuarios, erro := repositorio.Buscar(nomeOuNick)
+	usuarios, erro := repositorio.Buscar(nomeOuNick, solicitanteID, limite, (pagina-1)*limite)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if usuarios == nil {
+		usuarios = []models.Usuario{}
 	}
 
 	respostas.JSON(w, http.StatusOK, usuarios)
 }
 
-// BuscarUsuario busca um usuário expesifico no banco de dados
+// BuscarUsuario busca o perfil de um usuário, com os totais e a relação com quem está vendo
 func BuscarUsuario(w http.ResponseWriter, r *http.Request) {
 	parametros := mux.Vars(r)
 
@@ -82,20 +122,44 @@ func BuscarUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	solicitanteID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
 	db, erro := banco.Conectar()
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
 	}
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDeUsuarios(db)
-	usuario, erro := repositorio.BuscarPorId(usuarioID)
+	perfil, erro := repositorio.BuscarPerfil(usuarioID, solicitanteID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	bloqueado, erro := repositorio.ExisteBloqueio(usuarioID, solicitanteID)
 	if erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
-	respostas.JSON(w, http.StatusOK, usuario)
+	// Quem tem bloqueio com o usuário não fica sabendo que o perfil existe
+	if perfil.ID == 0 || bloqueado {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Usuário não encontrado."))
+		return
+	}
+
+	// O email só aparece para o próprio usuário e para os administradores
+	if usuarioID != solicitanteID && !autenticacao.PossuiPapel(r, autenticacao.PapelAdmin) {
+		perfil.Usuario = perfil.Usuario.Publico()
+	}
+
+	respostas.JSON(w, http.StatusOK, perfil)
 }
 
 // AtualizarUsuario altera as informações de usuário no banco de dados
@@ -113,7 +177,7 @@ func AtualizarUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if usuarioID != usuarioIDNoToken {
+	if usuarioID != usuarioIDNoToken && !autenticacao.PossuiPapel(r, autenticacao.PapelAdmin) {
 		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível atualizar um usuário que não seja o seu."))
 		return
 	}
@@ -143,11 +207,24 @@ func AtualizarUsuario(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	usuarioSalvoNoBanco, erro := repositorio.BuscarPorId(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
 	if erro = repositorio.Atualizar(usuarioID, usuario); erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
 	}
 
+	// Trocar o email exige uma nova verificação
+	if usuarioSalvoNoBanco.Email != usuario.Email {
+		if erro = enviarVerificacaoDeEmail(db, usuarioID, usuario.Email); erro != nil {
+			log.Printf("erro ao enviar verificação de email: %v", erro)
+		}
+	}
+
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -166,7 +243,7 @@ func DeletarUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if usuarioID != usuarioIDNoToken {
+	if usuarioID != usuarioIDNoToken && !autenticacao.PossuiPapel(r, autenticacao.PapelAdmin) {
 		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível deletar um usuário que não seja o seu."))
 		return
 	}
@@ -215,6 +292,46 @@ func SeguirUsuario(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	bloqueado, erro := repositorio.ExisteBloqueio(usuarioID, seguidorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if bloqueado {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível seguir este usuário."))
+		return
+	}
+
+	usuario, erro := repositorio.BuscarPorId(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	segue, erro := repositorio.Segue(usuarioID, seguidorID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	// Quem já segue o perfil não precisa de uma nova aprovação, mesmo que ele tenha ficado privado depois
+	if segue {
+		respostas.JSON(w, http.StatusNoContent, nil)
+		return
+	}
+
+	// Perfis privados recebem um pedido que precisa ser aprovado pelo dono
+	if usuario.Privado {
+		if erro = repositorio.SolicitarSeguir(usuarioID, seguidorID); erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		respostas.JSON(w, http.StatusAccepted, nil)
+		return
+	}
+
 	if erro = repositorio.Seguir(usuarioID, seguidorID); erro != nil {
 		respostas.Erro(w, http.StatusInternalServerError, erro)
 		return
@@ -259,6 +376,320 @@ func PararDeSeguirUsuario(w http.ResponseWriter, r *http.Request) {
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
 
+// AtualizarPrivacidade torna o perfil do usuário privado ou público
+func AtualizarPrivacidade(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	if usuarioID != usuarioIDNoToken {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível alterar a privacidade de um usuário que não seja o seu."))
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var privacidade models.Privacidade
+	if erro = json.Unmarshal(corpoRequisicao, &privacidade); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	if erro = repositorio.AtualizarPrivacidade(usuarioID, privacidade.Privado); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarSolicitacoesSeguir traz os pedidos pendentes para seguir o usuário logado
+func BuscarSolicitacoesSeguir(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	solicitacoes, erro := repositorio.BuscarSolicitacoesSeguir(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, solicitacoes)
+}
+
+// AprovarSolicitacaoSeguir aceita o pedido de um usuário para seguir o usuário logado
+func AprovarSolicitacaoSeguir(w http.ResponseWriter, r *http.Request) {
+	responderSolicitacaoSeguir(w, r, true)
+}
+
+// RejeitarSolicitacaoSeguir recusa o pedido de um usuário para seguir o usuário logado
+func RejeitarSolicitacaoSeguir(w http.ResponseWriter, r *http.Request) {
+	responderSolicitacaoSeguir(w, r, false)
+}
+
+func responderSolicitacaoSeguir(w http.ResponseWriter, r *http.Request, aprovar bool) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	solicitanteID, erro := strconv.ParseUint(parametros["solicitanteId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	respondida, erro := repositorio.ResponderSolicitacaoSeguir(usuarioID, solicitanteID, aprovar)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !respondida {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Não há pedido pendente deste usuário."))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BloquearUsuario impede que outro usuário interaja com o usuário logado e desfaz o follow entre eles
+func BloquearUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioIDNoToken == usuarioID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível bloquear você mesmo."))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	usuario, erro := repositorio.BuscarPorId(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if usuario.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Usuário não encontrado."))
+		return
+	}
+
+	if erro = repositorio.Bloquear(usuarioIDNoToken, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DesbloquearUsuario desfaz um bloqueio feito pelo usuário logado
+func DesbloquearUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioIDNoToken == usuarioID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível desbloquear você mesmo."))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	if erro = repositorio.Desbloquear(usuarioIDNoToken, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// SilenciarUsuario esconde do feed as publicações de outro usuário sem deixar de segui-lo
+func SilenciarUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if usuarioIDNoToken == usuarioID {
+		respostas.Erro(w, http.StatusForbidden, errors.New("Não é possível silenciar você mesmo."))
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	// O corpo é opcional: sem ele, o usuário fica silenciado até ser dessilenciado
+	var silenciamento models.Silenciamento
+	if len(corpoRequisicao) > 0 {
+		if erro = json.Unmarshal(corpoRequisicao, &silenciamento); erro != nil {
+			respostas.Erro(w, http.StatusBadRequest, erro)
+			return
+		}
+	}
+
+	if erro = silenciamento.Validar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	if erro = repositorio.Silenciar(usuarioIDNoToken, usuarioID, silenciamento.ExpiraEm); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// DessilenciarUsuario volta a mostrar no feed as publicações de um usuário silenciado
+func DessilenciarUsuario(w http.ResponseWriter, r *http.Request) {
+	usuarioIDNoToken, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	if erro = repositorio.Dessilenciar(usuarioIDNoToken, usuarioID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarSilenciados traz os usuários silenciados pelo usuário logado
+func BuscarSilenciados(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	silenciados, erro := repositorio.BuscarSilenciados(usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, silenciados)
+}
+
 // BuscarSeguidores traz todos os seguidores de um usuário
 func BuscarSeguidores(w http.ResponseWriter, r *http.Request) {
 	parametros := mux.Vars(r)
@@ -371,3 +802,51 @@ func AtualizarSenha(w http.ResponseWriter, r *http.Request) {
 
 	respostas.JSON(w, http.StatusNoContent, nil)
 }
+
+// AtualizarPapelUsuario altera o papel (usuario, moderador ou admin) de um usuário
+func AtualizarPapelUsuario(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	usuarioID, erro := strconv.ParseUint(parametros["usuarioId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var papel models.Papel
+	if erro = json.Unmarshal(corpoRequisicao, &papel); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if !autenticacao.PapelValido(papel.Papel) {
+		respostas.Erro(w, http.StatusBadRequest, errors.New("O papel informado é inválido"))
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeUsuarios(db)
+	atualizado, erro := repositorio.AtualizarPapel(usuarioID, papel.Papel)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if !atualizado {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Usuário não encontrado."))
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/respostas"
+	"api/src/seguranca"
+	"api/src/webhooks"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CriarWebhook cadastra uma URL para receber os eventos escolhidos. O segredo usado nas assinaturas
+// só é mostrado nesta resposta; se não for informado, um é gerado.
+func CriarWebhook(w http.ResponseWriter, r *http.Request) {
+	usuarioID, erro := autenticacao.ExtrairUsuarioID(r)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnauthorized, erro)
+		return
+	}
+
+	corpoRequisicao, erro := io.ReadAll(r.Body)
+	if erro != nil {
+		respostas.Erro(w, http.StatusUnprocessableEntity, erro)
+		return
+	}
+
+	var webhook models.Webhook
+	if erro = json.Unmarshal(corpoRequisicao, &webhook); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if erro = webhook.Preparar(); erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	if webhook.Segredo == "" {
+		if webhook.Segredo, erro = seguranca.GerarToken(); erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeWebhooks(db)
+	webhook.ID, erro = repositorio.Criar(webhook, usuarioID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	webhook.Ativo = true
+	respostas.JSON(w, http.StatusCreated, webhook)
+}
+
+// BuscarWebhooks traz todos os webhooks cadastrados
+func BuscarWebhooks(w http.ResponseWriter, r *http.Request) {
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeWebhooks(db)
+	webhooks, erro := repositorio.Buscar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, webhooks)
+}
+
+// DeletarWebhook exclui um webhook e o seu histórico de entregas
+func DeletarWebhook(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	webhookID, erro := strconv.ParseUint(parametros["webhookId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeWebhooks(db)
+	webhook, erro := repositorio.BuscarPorID(webhookID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if webhook.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Webhook não encontrado."))
+		return
+	}
+
+	if erro = repositorio.Deletar(webhookID); erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusNoContent, nil)
+}
+
+// BuscarEntregasWebhook traz o histórico de entregas de um webhook, das mais recentes para as mais antigas
+func BuscarEntregasWebhook(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	webhookID, erro := strconv.ParseUint(parametros["webhookId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	limite, erro := lerLimite(r, 50, 200)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeWebhooks(db)
+	webhook, erro := repositorio.BuscarPorID(webhookID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if webhook.ID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Webhook não encontrado."))
+		return
+	}
+
+	entregas, erro := repositorio.BuscarEntregas(webhookID, limite)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	respostas.JSON(w, http.StatusOK, entregas)
+}
+
+// ReenviarEntregaWebhook agenda uma nova entrega com o mesmo payload de uma entrega anterior
+func ReenviarEntregaWebhook(w http.ResponseWriter, r *http.Request) {
+	parametros := mux.Vars(r)
+	webhookID, erro := strconv.ParseUint(parametros["webhookId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	entregaID, erro := strconv.ParseUint(parametros["entregaId"], 10, 64)
+	if erro != nil {
+		respostas.Erro(w, http.StatusBadRequest, erro)
+		return
+	}
+
+	db, erro := banco.Conectar()
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeWebhooks(db)
+	novaEntregaID, erro := repositorio.Reenviar(webhookID, entregaID)
+	if erro != nil {
+		respostas.Erro(w, http.StatusInternalServerError, erro)
+		return
+	}
+
+	if novaEntregaID == 0 {
+		respostas.Erro(w, http.StatusNotFound, errors.New("Entrega não encontrada."))
+		return
+	}
+
+	webhooks.Acordar()
+
+	respostas.JSON(w, http.StatusAccepted, models.EntregaWebhook{
+		ID:        novaEntregaID,
+		WebhookID: webhookID,
+		Status:    models.StatusEntregaPendente,
+	})
+}
@@ -0,0 +1,33 @@
+package email
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Arquivo grava os emails em disco (ou no log) em vez de enviá-los, útil para desenvolvimento e testes
+type Arquivo struct {
+	Diretorio string
+}
+
+// Enviar grava a mensagem em um arquivo .eml no diretório configurado ou, sem diretório, no log
+func (mailer Arquivo) Enviar(mensagem Mensagem) error {
+	conteudo := montar("nao-responda@localhost", mensagem)
+
+	if mailer.Diretorio == "" {
+		log.Printf("email para %s:\n%s", mensagem.Para, conteudo)
+		return nil
+	}
+
+	if erro := os.MkdirAll(mailer.Diretorio, 0o755); erro != nil {
+		return erro
+	}
+
+	destinatario := strings.NewReplacer("/", "_", "\\", "_").Replace(mensagem.Para)
+	nome := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), destinatario)
+	return os.WriteFile(filepath.Join(mailer.Diretorio, nome), conteudo, 0o644)
+}
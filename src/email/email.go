@@ -0,0 +1,30 @@
+package email
+
+import "api/src/config"
+
+// Mensagem representa um email a ser enviado pela API
+type Mensagem struct {
+	Para    string
+	Assunto string
+	Corpo   string
+}
+
+// Mailer representa qualquer forma de entregar um email
+type Mailer interface {
+	Enviar(mensagem Mensagem) error
+}
+
+// NovoMailer retorna o mailer definido nas variáveis de ambiente
+func NovoMailer() Mailer {
+	if config.Mailer == "smtp" {
+		return SMTP{
+			Host:      config.SMTPHost,
+			Porta:     config.SMTPPorta,
+			Usuario:   config.SMTPUsuario,
+			Senha:     config.SMTPSenha,
+			Remetente: config.SMTPRemetente,
+		}
+	}
+
+	return Arquivo{Diretorio: config.MailerDiretorio}
+}
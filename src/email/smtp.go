@@ -0,0 +1,41 @@
+package email
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// SMTP envia emails através de um servidor SMTP
+type SMTP struct {
+	Host      string
+	Porta     int
+	Usuario   string
+	Senha     string
+	Remetente string
+}
+
+// Enviar entrega a mensagem ao servidor SMTP configurado
+func (mailer SMTP) Enviar(mensagem Mensagem) error {
+	endereco := fmt.Sprintf("%s:%d", mailer.Host, mailer.Porta)
+
+	var auth smtp.Auth
+	if mailer.Usuario != "" {
+		auth = smtp.PlainAuth("", mailer.Usuario, mailer.Senha, mailer.Host)
+	}
+
+	return smtp.SendMail(endereco, auth, mailer.Remetente, []string{mensagem.Para}, montar(mailer.Remetente, mensagem))
+}
+
+func montar(remetente string, mensagem Mensagem) []byte {
+	var corpo strings.Builder
+	fmt.Fprintf(&corpo, "From: %s\r\n", remetente)
+	fmt.Fprintf(&corpo, "To: %s\r\n", mensagem.Para)
+	fmt.Fprintf(&corpo, "Subject: %s\r\n", mensagem.Assunto)
+	corpo.WriteString("MIME-Version: 1.0\r\n")
+	corpo.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	corpo.WriteString("\r\n")
+	corpo.WriteString(mensagem.Corpo)
+
+	return []byte(corpo.String())
+}
@@ -0,0 +1,18 @@
+// Package espera calcula quanto esperar entre as tentativas dos trabalhos em segundo plano que repetem o que falhou,
+// como o despachante de eventos de domínio e o de webhooks
+package espera
+
+import "time"
+
+// Exponencial retorna a espera antes da próxima tentativa depois de tentativas falhas: inicial até a segunda
+// tentativa, dobrando a cada falha seguinte até chegar a maximo
+func Exponencial(tentativas uint64, inicial, maximo time.Duration) time.Duration {
+	atraso := inicial
+	for i := uint64(1); i < tentativas; i++ {
+		atraso *= 2
+		if atraso >= maximo {
+			return maximo
+		}
+	}
+	return atraso
+}
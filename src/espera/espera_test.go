@@ -0,0 +1,35 @@
+package espera
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponencial(t *testing.T) {
+	testes := []struct {
+		tentativas uint64
+		inicial    time.Duration
+		maximo     time.Duration
+		esperado   time.Duration
+	}{
+		{0, 30 * time.Second, 6 * time.Hour, 30 * time.Second},
+		{1, 30 * time.Second, 6 * time.Hour, 30 * time.Second},
+		{2, 30 * time.Second, 6 * time.Hour, time.Minute},
+		{3, 30 * time.Second, 6 * time.Hour, 2 * time.Minute},
+		{8, 30 * time.Second, 6 * time.Hour, 64 * time.Minute},
+		{10, 30 * time.Second, 6 * time.Hour, 256 * time.Minute},
+		{11, 30 * time.Second, 6 * time.Hour, 6 * time.Hour},
+		{1000, 30 * time.Second, 6 * time.Hour, 6 * time.Hour},
+		{1, 5 * time.Second, time.Hour, 5 * time.Second},
+		{9, 5 * time.Second, time.Hour, 1280 * time.Second},
+		{10, 5 * time.Second, time.Hour, 2560 * time.Second},
+		{11, 5 * time.Second, time.Hour, time.Hour},
+	}
+
+	for _, teste := range testes {
+		if atraso := Exponencial(teste.tentativas, teste.inicial, teste.maximo); atraso != teste.esperado {
+			t.Errorf("Exponencial(%d, %s, %s) = %s, esperado %s",
+				teste.tentativas, teste.inicial, teste.maximo, atraso, teste.esperado)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package eventos
+
+import (
+	"api/src/banco"
+	"api/src/espera"
+	"api/src/models"
+	"api/src/repository"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// MaximoTentativas é quantas vezes um evento é entregue antes de ser marcado como falha
+	MaximoTentativas = 10
+
+	// atrasoInicial é a espera antes da segunda tentativa; as seguintes dobram até atrasoMaximo
+	atrasoInicial = 5 * time.Second
+	atrasoMaximo  = time.Hour
+
+	intervaloVerificacao = time.Second
+	reservaEvento        = time.Minute
+	eventosPorRodada     = 50
+)
+
+// Iniciar registra os manipuladores da API e coloca o despachante da outbox para rodar em segundo plano
+func Iniciar() {
+	registrarManipuladores()
+
+	go func() {
+		verificacao := time.NewTicker(intervaloVerificacao)
+		defer verificacao.Stop()
+
+		// A conexão é mantida entre as rodadas e refeita se o banco ainda não estiver disponível
+		var db *sql.DB
+		for range verificacao.C {
+			if db == nil {
+				var erro error
+				if db, erro = banco.Conectar(); erro != nil {
+					log.Printf("erro ao conectar para entregar eventos: %v", erro)
+					continue
+				}
+			}
+
+			// Rodadas cheias indicam que ainda pode haver eventos esperando
+			for processarPendentes(db) == eventosPorRodada {
+			}
+		}
+	}()
+}
+
+// processarPendentes entrega os eventos pendentes e retorna quantos foram reservados
+func processarPendentes(db *sql.DB) int {
+	repositorio := repository.NovoRepositorioDeOutbox(db)
+	eventos, erro := repositorio.ReservarPendentes(eventosPorRodada, reservaEvento)
+	if erro != nil {
+		log.Printf("erro ao buscar eventos pendentes da outbox: %v", erro)
+		return 0
+	}
+
+	for _, evento := range eventos {
+		if erro = entregar(db, repositorio, evento); erro == nil {
+			if erro = repositorio.MarcarProcessado(evento.ID); erro != nil {
+				log.Printf("erro ao marcar evento %d como processado: %v", evento.ID, erro)
+			}
+			continue
+		}
+
+		desistir := evento.Tentativas >= MaximoTentativas
+		if desistir {
+			log.Printf("evento %d (%s) descartado após %d tentativas: %v", evento.ID, evento.Tipo, evento.Tentativas, erro)
+		}
+
+		atraso := espera.Exponencial(evento.Tentativas, atrasoInicial, atrasoMaximo)
+		if erro = repositorio.RegistrarFalha(evento.ID, erro.Error(), atraso, desistir); erro != nil {
+			log.Printf("erro ao registrar falha do evento %d: %v", evento.ID, erro)
+		}
+	}
+
+	return len(eventos)
+}
+
+// entregar chama os manipuladores do evento que ainda não o trataram, registrando cada um que termina com sucesso
+func entregar(db *sql.DB, repositorio *repository.Outbox, evento models.EventoDominio) error {
+	concluidos := make(map[string]bool)
+	for _, nome := range evento.Concluidos {
+		concluidos[nome] = true
+	}
+
+	var falhas []string
+	for _, registro := range manipuladores[evento.Tipo] {
+		if concluidos[registro.nome] {
+			continue
+		}
+
+		if erro := registro.manipulador(db, evento); erro != nil {
+			falhas = append(falhas, fmt.Sprintf("%s: %v", registro.nome, erro))
+			continue
+		}
+
+		if erro := repositorio.ConcluirManipulador(evento.ID, registro.nome); erro != nil {
+			falhas = append(falhas, fmt.Sprintf("%s: %v", registro.nome, erro))
+		}
+	}
+
+	if len(falhas) > 0 {
+		return fmt.Errorf("%s", strings.Join(falhas, "; "))
+	}
+
+	return nil
+}
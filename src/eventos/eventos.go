@@ -0,0 +1,23 @@
+package eventos
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// Manipulador trata um evento de domínio. Um erro faz o evento ser entregue de novo mais tarde,
+// então o manipulador precisa tolerar receber o mesmo evento mais de uma vez.
+type Manipulador func(db *sql.DB, evento models.EventoDominio) error
+
+type registro struct {
+	nome        string
+	manipulador Manipulador
+}
+
+var manipuladores = make(map[string][]registro)
+
+// Registrar inscreve um manipulador para um tipo de evento. O nome identifica o manipulador na outbox
+// e não deve mudar, pois é ele que evita repetir manipuladores que já trataram o evento.
+func Registrar(tipo, nome string, manipulador Manipulador) {
+	manipuladores[tipo] = append(manipuladores[tipo], registro{nome, manipulador})
+}
@@ -0,0 +1,208 @@
+package eventos
+
+import (
+	"api/src/armazenamento"
+	"api/src/hub"
+	"api/src/models"
+	"api/src/repository"
+	"api/src/webhooks"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sort"
+)
+
+// registrarManipuladores liga os efeitos colaterais da API (notificações, eventos em tempo real e webhooks)
+// aos eventos de domínio que os disparam
+func registrarManipuladores() {
+	Registrar(models.EventoPublicacaoCriada, "notificacoes.mencoes", notificarMencoes)
+	Registrar(models.EventoPublicacaoEditada, "notificacoes.mencoes", notificarMencoes)
+	Registrar(models.EventoPublicacaoCriada, "tempo_real.publicacao", transmitirPublicacao)
+	Registrar(models.EventoPublicacaoCurtida, "notificacoes.curtida", notificarCurtida)
+	Registrar(models.EventoUsuarioSeguido, "notificacoes.seguidor", notificarSeguidor)
+	Registrar(models.EventoUsuarioSeguirSolicitado, "notificacoes.solicitacao_seguir", notificarSolicitacaoSeguir)
+	Registrar(models.EventoMensagemEnviada, "tempo_real.mensagem", transmitirMensagem)
+	Registrar(models.EventoPublicacaoExcluida, "armazenamento.midias", removerMidias)
+	Registrar(models.EventoMidiasDescartadas, "armazenamento.midias", removerMidias)
+
+	for _, tipo := range []string{
+		models.EventoUsuarioCriado,
+		models.EventoUsuarioSeguido,
+		models.EventoPublicacaoCriada,
+		models.EventoPublicacaoEditada,
+		models.EventoPublicacaoExcluida,
+	} {
+		Registrar(tipo, "webhooks", dispararWebhooks)
+	}
+}
+
+// notificarMencoes avisa cada usuário mencionado uma única vez por publicação, mesmo que ela seja editada
+// depois ou que o evento seja entregue de novo
+func notificarMencoes(db *sql.DB, evento models.EventoDominio) error {
+	var publicacao models.Publicacao
+	if erro := json.Unmarshal(evento.Dados, &publicacao); erro != nil {
+		return erro
+	}
+
+	repositorio := repository.NovoRepositorioDeNotificacoes(db)
+	notificados := make(map[uint64]bool)
+	for _, mencao := range publicacao.Mencoes {
+		if notificados[mencao.UsuarioID] {
+			continue
+		}
+		notificados[mencao.UsuarioID] = true
+
+		existe, erro := repositorio.ExisteParaPublicacao(mencao.UsuarioID, models.NotificacaoMencao, publicacao.ID)
+		if erro != nil {
+			return erro
+		}
+		if existe {
+			continue
+		}
+
+		if erro = notificar(db, mencao.UsuarioID, models.NotificacaoMencao, publicacao.AutorID, publicacao.ID); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
+
+func notificarCurtida(db *sql.DB, evento models.EventoDominio) error {
+	var curtida models.Curtida
+	if erro := json.Unmarshal(evento.Dados, &curtida); erro != nil {
+		return erro
+	}
+
+	return notificar(db, curtida.AutorID, models.NotificacaoCurtida, curtida.UsuarioID, curtida.PublicacaoID)
+}
+
+func notificarSeguidor(db *sql.DB, evento models.EventoDominio) error {
+	var seguimento models.Seguimento
+	if erro := json.Unmarshal(evento.Dados, &seguimento); erro != nil {
+		return erro
+	}
+
+	// Quem aprovou o pedido já sabe que ganhou o seguidor
+	if seguimento.Aprovado {
+		return nil
+	}
+
+	return notificar(db, seguimento.UsuarioID, models.NotificacaoSeguidor, seguimento.SeguidorID, 0)
+}
+
+func notificarSolicitacaoSeguir(db *sql.DB, evento models.EventoDominio) error {
+	var seguimento models.Seguimento
+	if erro := json.Unmarshal(evento.Dados, &seguimento); erro != nil {
+		return erro
+	}
+
+	return notificar(db, seguimento.UsuarioID, models.NotificacaoSolicitacaoSeguir, seguimento.SeguidorID, 0)
+}
+
+// notificar registra uma notificação e a envia em tempo real para o usuário
+func notificar(db *sql.DB, usuarioID uint64, tipo string, atorID, publicacaoID uint64) error {
+	notificacaoID, erro := repository.NovoRepositorioDeNotificacoes(db).Criar(usuarioID, tipo, atorID, publicacaoID)
+	if erro != nil {
+		return erro
+	}
+
+	if notificacaoID == 0 {
+		return nil
+	}
+
+	ator, erro := repository.NovoRepositorioDeUsuarios(db).BuscarPorId(atorID)
+	if erro != nil {
+		// A notificação já foi gravada; sem o autor, ela só não é enviada em tempo real
+		log.Printf("erro ao buscar o autor da notificação: %v", erro)
+		return nil
+	}
+
+	notificacao := models.Notificacao{
+		ID:           notificacaoID,
+		Tipo:         tipo,
+		PublicacaoID: publicacaoID,
+		Atores:       []models.AtorNotificacao{{UsuarioID: ator.ID, Nick: ator.Nick}},
+		TotalAtores:  1,
+	}
+	notificacao.GerarTexto()
+
+	// A notificação já foi gravada; repetir o manipulador criaria outra, então a falha só fica no log
+	if erro = hub.Publicar(hub.EventoNotificacao, notificacao, usuarioID); erro != nil {
+		log.Printf("erro ao publicar a notificação em tempo real: %v", erro)
+	}
+	return nil
+}
+
+// transmitirPublicacao envia a nova publicação em tempo real para os seguidores do autor que a veriam no feed
+func transmitirPublicacao(db *sql.DB, evento models.EventoDominio) error {
+	var publicacao models.Publicacao
+	if erro := json.Unmarshal(evento.Dados, &publicacao); erro != nil {
+		return erro
+	}
+
+	seguidores, erro := repository.NovoRepositorioDeUsuarios(db).BuscarFiltrosDeSeguidores(publicacao.AutorID)
+	if erro != nil {
+		return erro
+	}
+
+	return hub.Publicar(hub.EventoPublicacao, publicacao, destinatariosPublicacao(publicacao, seguidores)...)
+}
+
+// destinatariosPublicacao aplica aos seguidores (já sem quem silenciou o autor) os filtros de palavras do feed:
+// quem filtra alguma palavra do título ou do conteúdo não recebe a publicação
+func destinatariosPublicacao(publicacao models.Publicacao, seguidores map[uint64][]string) []uint64 {
+	var destinatarios []uint64
+	for seguidorID, palavras := range seguidores {
+		if !publicacao.ContemAlgumaPalavra(palavras) {
+			destinatarios = append(destinatarios, seguidorID)
+		}
+	}
+	sort.Slice(destinatarios, func(i, j int) bool { return destinatarios[i] < destinatarios[j] })
+
+	return destinatarios
+}
+
+// transmitirMensagem envia a nova mensagem em tempo real para os outros participantes da conversa
+func transmitirMensagem(db *sql.DB, evento models.EventoDominio) error {
+	var mensagem models.Mensagem
+	if erro := json.Unmarshal(evento.Dados, &mensagem); erro != nil {
+		return erro
+	}
+
+	participantes, erro := repository.NovoRepositorioDeConversas(db).BuscarParticipantes(mensagem.ConversaID)
+	if erro != nil {
+		return erro
+	}
+
+	var destinatarios []uint64
+	for _, participante := range participantes {
+		if participante.UsuarioID != mensagem.RemetenteID {
+			destinatarios = append(destinatarios, participante.UsuarioID)
+		}
+	}
+
+	return hub.Publicar(hub.EventoMensagem, mensagem, destinatarios...)
+}
+
+// removerMidias apaga do armazenamento os arquivos de uma publicação excluída ou de imagens de perfil descartadas.
+// Os dois eventos trazem as chaves no mesmo campo.
+func removerMidias(_ *sql.DB, evento models.EventoDominio) error {
+	var descartadas models.MidiasDescartadas
+	if erro := json.Unmarshal(evento.Dados, &descartadas); erro != nil {
+		return erro
+	}
+
+	destino := armazenamento.Novo()
+	for _, chave := range descartadas.ChavesMidias {
+		if erro := destino.Remover(chave); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
+
+func dispararWebhooks(db *sql.DB, evento models.EventoDominio) error {
+	return webhooks.Disparar(db, evento.Tipo, evento.CriadoEm, evento.Dados)
+}
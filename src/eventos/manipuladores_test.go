@@ -0,0 +1,34 @@
+package eventos
+
+import (
+	"api/src/models"
+	"reflect"
+	"testing"
+)
+
+func TestDestinatariosPublicacao(t *testing.T) {
+	publicacao := models.Publicacao{Titulo: "Resultado da Eleição", Conteudo: "Saiu a apuração do segundo turno."}
+
+	casos := []struct {
+		nome       string
+		seguidores map[uint64][]string
+		esperado   []uint64
+	}{
+		{"sem seguidores", nil, nil},
+		{"sem filtros", map[uint64][]string{3: {}, 1: nil, 2: {}}, []uint64{1, 2, 3}},
+		{"filtro no título sem acento", map[uint64][]string{1: {"eleicao"}, 2: {}}, []uint64{2}},
+		{"filtro no conteúdo", map[uint64][]string{1: {"futebol", "turno"}, 2: {"futebol"}}, []uint64{2}},
+		{"filtro dentro de outra palavra", map[uint64][]string{1: {"sul"}, 2: {"turn"}}, []uint64{1, 2}},
+		{"expressão", map[uint64][]string{1: {"segundo turno"}, 2: {"primeiro turno"}}, []uint64{2}},
+		{"todos filtram", map[uint64][]string{1: {"apuracao"}, 2: {"resultado"}}, nil},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			obtido := destinatariosPublicacao(publicacao, caso.seguidores)
+			if !reflect.DeepEqual(obtido, caso.esperado) {
+				t.Errorf("destinatariosPublicacao() = %v, esperado %v", obtido, caso.esperado)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package hub
+
+// Broker distribui os eventos entre as instâncias da API. Cada instância entrega
+// aos seus próprios clientes conectados os eventos recebidos pela função de Assinar.
+type Broker interface {
+	Publicar(evento Evento) error
+	Assinar(entregar func(Evento)) error
+	Fechar() error
+}
+
+// Memoria é o broker usado quando existe uma única instância da API
+type Memoria struct {
+	entregar func(Evento)
+}
+
+// Publicar entrega o evento diretamente para os clientes desta instância
+func (memoria *Memoria) Publicar(evento Evento) error {
+	if memoria.entregar != nil {
+		memoria.entregar(evento)
+	}
+	return nil
+}
+
+// Assinar registra a função que entrega os eventos aos clientes
+func (memoria *Memoria) Assinar(entregar func(Evento)) error {
+	memoria.entregar = entregar
+	return nil
+}
+
+// Fechar não tem nada a liberar no broker em memória
+func (memoria *Memoria) Fechar() error {
+	return nil
+}
@@ -0,0 +1,17 @@
+package hub
+
+import "encoding/json"
+
+// Tipos de evento enviados em tempo real
+const (
+	EventoPublicacao  = "publicacao"
+	EventoNotificacao = "notificacao"
+	EventoMensagem    = "mensagem"
+)
+
+// Evento representa algo que deve ser entregue em tempo real para um ou mais usuários
+type Evento struct {
+	Tipo          string          `json:"tipo"`
+	Destinatarios []uint64        `json:"destinatarios"`
+	Dados         json.RawMessage `json:"dados"`
+}
@@ -0,0 +1,139 @@
+package hub
+
+import (
+	"api/src/config"
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// tamanhoBufferAssinatura é quantos eventos podem ficar esperando um cliente lento antes de serem descartados
+const tamanhoBufferAssinatura = 32
+
+// Assinatura representa uma conexão de um usuário esperando eventos
+type Assinatura struct {
+	UsuarioID uint64
+	Eventos   chan Evento
+}
+
+// Hub mantém os clientes conectados nesta instância e entrega a eles os eventos vindos do broker
+type Hub struct {
+	broker     Broker
+	mutex      sync.RWMutex
+	assinantes map[uint64]map[*Assinatura]struct{}
+}
+
+var padrao *Hub
+
+// NovoHub cria um hub que recebe os eventos pelo broker informado
+func NovoHub(broker Broker) (*Hub, error) {
+	hub := &Hub{
+		broker:     broker,
+		assinantes: make(map[uint64]map[*Assinatura]struct{}),
+	}
+
+	if erro := broker.Assinar(hub.entregar); erro != nil {
+		return nil, erro
+	}
+
+	return hub, nil
+}
+
+// Iniciar cria o hub padrão da API com o broker definido nas variáveis de ambiente
+func Iniciar() error {
+	var broker Broker = &Memoria{}
+	if config.Broker == "postgres" {
+		postgres, erro := NovoPostgres(config.StringConexaoBanco)
+		if erro != nil {
+			return erro
+		}
+		broker = postgres
+	}
+
+	hub, erro := NovoHub(broker)
+	if erro != nil {
+		return erro
+	}
+
+	padrao = hub
+	return nil
+}
+
+// Publicar envia um evento para os destinatários pelo hub padrão
+func Publicar(tipo string, dados interface{}, destinatarios ...uint64) error {
+	if padrao == nil || len(destinatarios) == 0 {
+		return nil
+	}
+
+	return padrao.Publicar(tipo, dados, destinatarios...)
+}
+
+// Assinar registra um cliente do usuário no hub padrão
+func Assinar(usuarioID uint64) *Assinatura {
+	return padrao.Assinar(usuarioID)
+}
+
+// Cancelar remove um cliente do hub padrão
+func Cancelar(assinatura *Assinatura) {
+	padrao.Cancelar(assinatura)
+}
+
+// Publicar envia um evento para os destinatários, em qualquer instância em que eles estejam conectados
+func (hub *Hub) Publicar(tipo string, dados interface{}, destinatarios ...uint64) error {
+	dadosJSON, erro := json.Marshal(dados)
+	if erro != nil {
+		return erro
+	}
+
+	return hub.broker.Publicar(Evento{
+		Tipo:          tipo,
+		Destinatarios: destinatarios,
+		Dados:         dadosJSON,
+	})
+}
+
+// Assinar registra um novo cliente do usuário
+func (hub *Hub) Assinar(usuarioID uint64) *Assinatura {
+	assinatura := &Assinatura{
+		UsuarioID: usuarioID,
+		Eventos:   make(chan Evento, tamanhoBufferAssinatura),
+	}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if hub.assinantes[usuarioID] == nil {
+		hub.assinantes[usuarioID] = make(map[*Assinatura]struct{})
+	}
+	hub.assinantes[usuarioID][assinatura] = struct{}{}
+
+	return assinatura
+}
+
+// Cancelar remove o cliente, que deixa de receber eventos
+func (hub *Hub) Cancelar(assinatura *Assinatura) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	assinaturas := hub.assinantes[assinatura.UsuarioID]
+	delete(assinaturas, assinatura)
+	if len(assinaturas) == 0 {
+		delete(hub.assinantes, assinatura.UsuarioID)
+	}
+}
+
+// entregar repassa o evento para os clientes conectados nesta instância, descartando-o para clientes que não dão conta
+func (hub *Hub) entregar(evento Evento) {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+
+	for _, usuarioID := range evento.Destinatarios {
+		for assinatura := range hub.assinantes[usuarioID] {
+			select {
+			case assinatura.Eventos <- evento:
+			default:
+				log.Printf("evento %s descartado para o usuário %d: cliente lento", evento.Tipo, usuarioID)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package hub
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// canalPostgres é o canal de LISTEN/NOTIFY usado para compartilhar os eventos
+const canalPostgres = "eventos_tempo_real"
+
+// tamanhoMaximoNotify é o limite de payload do NOTIFY no Postgres (8000 bytes), com alguma folga
+const tamanhoMaximoNotify = 7900
+
+// prefixoReferencia marca as notificações que trazem só o ID de um evento guardado em eventos_tempo_real
+const prefixoReferencia = "ref:"
+
+// Postgres compartilha os eventos entre várias instâncias da API usando LISTEN/NOTIFY
+type Postgres struct {
+	db       *sql.DB
+	listener *pq.Listener
+}
+
+// NovoPostgres cria um broker que usa o banco informado pela string de conexão
+func NovoPostgres(stringConexao string) (*Postgres, error) {
+	db, erro := sql.Open("postgres", stringConexao)
+	if erro != nil {
+		return nil, erro
+	}
+
+	listener := pq.NewListener(stringConexao, time.Second, time.Minute, func(_ pq.ListenerEventType, erro error) {
+		if erro != nil {
+			log.Printf("erro na conexão do broker de eventos: %v", erro)
+		}
+	})
+
+	return &Postgres{db: db, listener: listener}, nil
+}
+
+// Publicar envia o evento para todas as instâncias que estão escutando o canal
+func (postgres *Postgres) Publicar(evento Evento) error {
+	payload, erro := json.Marshal(evento)
+	if erro != nil {
+		return erro
+	}
+
+	if len(payload) > tamanhoMaximoNotify {
+		return postgres.publicarReferencia(payload)
+	}
+
+	_, erro = postgres.db.Exec(`SELECT pg_notify($1, $2)`, canalPostgres, string(payload))
+	return erro
+}
+
+// publicarReferencia guarda um evento que não cabe em um NOTIFY (muitos destinatários ou publicações com mídias)
+// e notifica só o ID dele. A notificação sai no commit, quando o evento já está visível para as outras instâncias.
+func (postgres *Postgres) publicarReferencia(payload []byte) error {
+	// Os eventos só precisam durar até as outras instâncias os buscarem
+	if _, erro := postgres.db.Exec(
+		`DELETE FROM eventos_tempo_real
+        WHERE criado_em < CURRENT_TIMESTAMP - INTERVAL '5 minutes'`,
+	); erro != nil {
+		return erro
+	}
+
+	_, erro := postgres.db.Exec(
+		`WITH evento AS (
+           INSERT INTO eventos_tempo_real (payload) VALUES ($2) RETURNING id
+        )
+        SELECT pg_notify($1, $3 || id) FROM evento`,
+		canalPostgres, string(payload), prefixoReferencia,
+	)
+	return erro
+}
+
+// buscarReferencia traz o evento guardado por publicarReferencia
+func (postgres *Postgres) buscarReferencia(id uint64) ([]byte, error) {
+	var payload string
+	erro := postgres.db.QueryRow(
+		`SELECT payload FROM eventos_tempo_real WHERE id = $1`,
+		id,
+	).Scan(&payload)
+	return []byte(payload), erro
+}
+
+// Assinar passa a escutar o canal e entrega cada evento recebido
+func (postgres *Postgres) Assinar(entregar func(Evento)) error {
+	if erro := postgres.listener.Listen(canalPostgres); erro != nil {
+		return erro
+	}
+
+	go func() {
+		for notificacao := range postgres.listener.Notify {
+			// Uma notificação nula indica que a conexão foi refeita e eventos podem ter se perdido
+			if notificacao == nil {
+				continue
+			}
+
+			payload := []byte(notificacao.Extra)
+			if referencia, ok := strings.CutPrefix(notificacao.Extra, prefixoReferencia); ok {
+				id, erro := strconv.ParseUint(referencia, 10, 64)
+				if erro == nil {
+					payload, erro = postgres.buscarReferencia(id)
+				}
+				if erro != nil {
+					log.Printf("erro ao buscar o evento %s do broker: %v", referencia, erro)
+					continue
+				}
+			}
+
+			var evento Evento
+			if erro := json.Unmarshal(payload, &evento); erro != nil {
+				log.Printf("evento inválido recebido do broker: %v", erro)
+				continue
+			}
+			entregar(evento)
+		}
+	}()
+
+	return nil
+}
+
+// Fechar encerra a escuta e a conexão usada para publicar
+func (postgres *Postgres) Fechar() error {
+	if erro := postgres.listener.Close(); erro != nil {
+		return erro
+	}
+	return postgres.db.Close()
+}
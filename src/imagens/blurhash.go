@@ -0,0 +1,115 @@
+package imagens
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+const caracteresBase83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// CodificarBlurhash gera o blurhash (https://blurha.sh) da imagem, um texto curto que os clientes
+// decodificam em um borrão colorido enquanto a imagem carrega. Os componentes vão de 1 a 9.
+func CodificarBlurhash(imagem image.Image, componentesX, componentesY int) string {
+	limites := imagem.Bounds()
+	largura, altura := limites.Dx(), limites.Dy()
+
+	// Os pixels são convertidos para linear uma única vez
+	linear := make([][3]float64, largura*altura)
+	for y := 0; y < altura; y++ {
+		for x := 0; x < largura; x++ {
+			r, g, b, _ := imagem.At(limites.Min.X+x, limites.Min.Y+y).RGBA()
+			linear[y*largura+x] = [3]float64{
+				sRGBParaLinear(int(r >> 8)),
+				sRGBParaLinear(int(g >> 8)),
+				sRGBParaLinear(int(b >> 8)),
+			}
+		}
+	}
+
+	fatores := make([][3]float64, 0, componentesX*componentesY)
+	for j := 0; j < componentesY; j++ {
+		for i := 0; i < componentesX; i++ {
+			normalizacao := 2.0
+			if i == 0 && j == 0 {
+				normalizacao = 1
+			}
+
+			var fator [3]float64
+			for y := 0; y < altura; y++ {
+				for x := 0; x < largura; x++ {
+					base := normalizacao *
+						math.Cos(math.Pi*float64(i)*float64(x)/float64(largura)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(altura))
+					pixel := linear[y*largura+x]
+					fator[0] += base * pixel[0]
+					fator[1] += base * pixel[1]
+					fator[2] += base * pixel[2]
+				}
+			}
+
+			escala := 1 / float64(largura*altura)
+			fatores = append(fatores, [3]float64{fator[0] * escala, fator[1] * escala, fator[2] * escala})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(base83((componentesX-1)+(componentesY-1)*9, 1))
+
+	maximoAC := 0.0
+	for _, fator := range fatores[1:] {
+		for _, canal := range fator {
+			maximoAC = math.Max(maximoAC, math.Abs(canal))
+		}
+	}
+
+	if len(fatores) > 1 {
+		maximoQuantizado := int(math.Max(0, math.Min(82, math.Floor(maximoAC*166-0.5))))
+		maximoAC = float64(maximoQuantizado+1) / 166
+		hash.WriteString(base83(maximoQuantizado, 1))
+	} else {
+		maximoAC = 1
+		hash.WriteString(base83(0, 1))
+	}
+
+	dc := fatores[0]
+	hash.WriteString(base83(linearParaSRGB(dc[0])<<16+linearParaSRGB(dc[1])<<8+linearParaSRGB(dc[2]), 4))
+
+	for _, fator := range fatores[1:] {
+		quantizar := func(valor float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(potenciaComSinal(valor/maximoAC, 0.5)*9+9.5))))
+		}
+		hash.WriteString(base83(quantizar(fator[0])*19*19+quantizar(fator[1])*19+quantizar(fator[2]), 2))
+	}
+
+	return hash.String()
+}
+
+func base83(valor, digitos int) string {
+	resultado := make([]byte, digitos)
+	for i := 1; i <= digitos; i++ {
+		digito := (valor / int(math.Pow(83, float64(digitos-i)))) % 83
+		resultado[i-1] = caracteresBase83[digito]
+	}
+	return string(resultado)
+}
+
+func sRGBParaLinear(valor int) float64 {
+	v := float64(valor) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearParaSRGB(valor float64) int {
+	v := math.Max(0, math.Min(1, valor))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func potenciaComSinal(valor, expoente float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(valor), expoente), valor)
+}
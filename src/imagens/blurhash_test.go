@@ -0,0 +1,53 @@
+package imagens
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCodificarBlurhash(t *testing.T) {
+	// Degradê 8x6: o vermelho cresce da esquerda para a direita e o verde de cima para baixo
+	degrade := image.NewNRGBA(image.Rect(0, 0, 8, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			degrade.Set(x, y, color.NRGBA{R: uint8(x * 255 / 7), G: uint8(y * 255 / 5), B: 128, A: 255})
+		}
+	}
+
+	// Os hashes esperados foram calculados com o algoritmo de referência (https://github.com/woltapp/blurhash)
+	casos := []struct {
+		nome         string
+		imagem       image.Image
+		componentesX int
+		componentesY int
+		esperado     string
+	}{
+		{"degradê", degrade, 4, 3, "LyI5er3AfQxtz4NKfQnSeXf7fQf7"},
+		{"branco", image.NewUniform(color.White), 4, 3, "L~TSUA~qfQ~q~q%MfQ%MfQfQfQfQ"},
+		{"vermelho só com a cor média", image.NewUniform(color.RGBA{R: 255, A: 255}), 1, 1, "00TI:j"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			imagem := caso.imagem
+			if _, uniforme := imagem.(*image.Uniform); uniforme {
+				imagem = recortar(imagem, 4, 4)
+			}
+			if hash := CodificarBlurhash(imagem, caso.componentesX, caso.componentesY); hash != caso.esperado {
+				t.Errorf("CodificarBlurhash() = %q, esperado %q", hash, caso.esperado)
+			}
+		})
+	}
+}
+
+// recortar transforma uma imagem infinita (como image.Uniform) em uma imagem do tamanho informado
+func recortar(imagem image.Image, largura, altura int) image.Image {
+	recorte := image.NewNRGBA(image.Rect(0, 0, largura, altura))
+	for y := 0; y < altura; y++ {
+		for x := 0; x < largura; x++ {
+			recorte.Set(x, y, imagem.At(x, y))
+		}
+	}
+	return recorte
+}
@@ -0,0 +1,83 @@
+package imagens
+
+import "errors"
+
+const (
+	// maximoQuadrosGIF e maximoPixelsGIF limitam o trabalho de decodificar um GIF animado: cada quadro é
+	// decodificado inteiro em memória, então o tamanho da tela sozinho não basta
+	maximoQuadrosGIF = 300
+	maximoPixelsGIF  = 50_000_000
+)
+
+var errGIFMalformado = errors.New("gif malformado")
+
+// medirGIF percorre os blocos do GIF sem decodificar os quadros e retorna quantos quadros ele tem e a soma
+// dos pixels de todos eles, como declarados nos descritores de imagem
+func medirGIF(conteudo []byte) (int, int, error) {
+	// Cabeçalho (6 bytes) e descritor da tela lógica (7 bytes)
+	if len(conteudo) < 13 {
+		return 0, 0, errGIFMalformado
+	}
+	posicao := 13
+	if conteudo[10]&0x80 != 0 {
+		posicao += 3 << (conteudo[10]&0x07 + 1)
+	}
+
+	var quadros, pixels int
+	for posicao < len(conteudo) {
+		switch conteudo[posicao] {
+		case 0x21: // Extensão: rótulo e sub-blocos
+			fim, erro := pularSubBlocos(conteudo, posicao+2)
+			if erro != nil {
+				return 0, 0, erro
+			}
+			posicao = fim
+		case 0x2C: // Descritor de imagem
+			if posicao+10 > len(conteudo) {
+				return 0, 0, errGIFMalformado
+			}
+			largura := int(conteudo[posicao+5]) | int(conteudo[posicao+6])<<8
+			altura := int(conteudo[posicao+7]) | int(conteudo[posicao+8])<<8
+			opcoes := conteudo[posicao+9]
+
+			quadros++
+			pixels += largura * altura
+			if quadros > maximoQuadrosGIF || pixels > maximoPixelsGIF {
+				return quadros, pixels, nil
+			}
+
+			posicao += 10
+			if opcoes&0x80 != 0 {
+				posicao += 3 << (opcoes&0x07 + 1)
+			}
+			// Tamanho mínimo do código LZW e os sub-blocos com os dados
+			fim, erro := pularSubBlocos(conteudo, posicao+1)
+			if erro != nil {
+				return 0, 0, erro
+			}
+			posicao = fim
+		case 0x3B: // Fim do arquivo
+			return quadros, pixels, nil
+		default:
+			return 0, 0, errGIFMalformado
+		}
+	}
+
+	// O arquivo acabou antes do bloco de fim
+	return 0, 0, errGIFMalformado
+}
+
+// pularSubBlocos avança por uma sequência de sub-blocos (tamanho seguido dos dados) até o bloco vazio que a encerra
+func pularSubBlocos(conteudo []byte, posicao int) (int, error) {
+	for {
+		if posicao >= len(conteudo) {
+			return 0, errGIFMalformado
+		}
+		tamanho := int(conteudo[posicao])
+		posicao++
+		if tamanho == 0 {
+			return posicao, nil
+		}
+		posicao += tamanho
+	}
+}
@@ -0,0 +1,117 @@
+package imagens
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// gifAnimado codifica um GIF com a quantidade de quadros informada, todos do mesmo tamanho
+func gifAnimado(t *testing.T, quadros, largura, altura int) []byte {
+	t.Helper()
+
+	paleta := color.Palette{color.Black, color.White}
+	animacao := &gif.GIF{}
+	for i := 0; i < quadros; i++ {
+		quadro := image.NewPaletted(image.Rect(0, 0, largura, altura), paleta)
+		quadro.SetColorIndex(0, 0, uint8(i%2))
+		animacao.Image = append(animacao.Image, quadro)
+		animacao.Delay = append(animacao.Delay, 10)
+	}
+
+	var conteudo bytes.Buffer
+	if erro := gif.EncodeAll(&conteudo, animacao); erro != nil {
+		t.Fatal(erro)
+	}
+	return conteudo.Bytes()
+}
+
+// gifDeclarado monta um GIF de um quadro sem dados, com o tamanho que o descritor de imagem declara
+func gifDeclarado(largura, altura uint16) []byte {
+	return []byte{
+		'G', 'I', 'F', '8', '9', 'a',
+		0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, // Tela lógica 1x1, sem tabela de cores global
+		0x2C, 0x00, 0x00, 0x00, 0x00,
+		byte(largura), byte(largura >> 8), byte(altura), byte(altura >> 8), 0x00,
+		0x02, 0x00, // Código LZW e nenhum sub-bloco
+		0x3B,
+	}
+}
+
+func TestMedirGIF(t *testing.T) {
+	casos := []struct {
+		nome     string
+		conteudo []byte
+		quadros  int
+		pixels   int
+	}{
+		{"um quadro", gifAnimado(t, 1, 10, 20), 1, 200},
+		{"animado", gifAnimado(t, 3, 10, 10), 3, 300},
+		{"tamanho declarado", gifDeclarado(1000, 500), 1, 500_000},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			quadros, pixels, erro := medirGIF(caso.conteudo)
+			if erro != nil || quadros != caso.quadros || pixels != caso.pixels {
+				t.Errorf("medirGIF() = (%d, %d, %v), esperado (%d, %d, nil)", quadros, pixels, erro, caso.quadros, caso.pixels)
+			}
+		})
+	}
+}
+
+func TestMedirGIFMalformado(t *testing.T) {
+	valido := gifAnimado(t, 3, 10, 10)
+
+	casos := []struct {
+		nome     string
+		conteudo []byte
+	}{
+		{"vazio", nil},
+		{"só o cabeçalho", []byte("GIF89a")},
+		{"bloco desconhecido", append(append([]byte(nil), gifDeclarado(1, 1)[:13]...), 0x00)},
+		{"tabela de cores cortada", []byte{'G', 'I', 'F', '8', '9', 'a', 0x01, 0x00, 0x01, 0x00, 0x87, 0x00, 0x00, 0x2C}},
+		{"descritor cortado", gifDeclarado(1, 1)[:18]},
+		{"sub-blocos sem fim", gifDeclarado(1, 1)[:24]},
+		{"sem bloco de fim", valido[:len(valido)-1]},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if _, _, erro := medirGIF(caso.conteudo); erro == nil {
+				t.Error("medirGIF() não retornou erro")
+			}
+		})
+	}
+
+	// Um arquivo cortado em qualquer ponto não pode derrubar a leitura nem contar quadros a mais
+	for tamanho := range valido {
+		if quadros, _, _ := medirGIF(valido[:tamanho]); quadros > 3 {
+			t.Errorf("medirGIF(cortado em %d) contou %d quadros", tamanho, quadros)
+		}
+	}
+}
+
+func TestProcessarGIFLimites(t *testing.T) {
+	casos := []struct {
+		nome     string
+		conteudo []byte
+		erro     error
+	}{
+		{"no limite de quadros", gifAnimado(t, maximoQuadrosGIF, 1, 1), nil},
+		{"quadros demais", gifAnimado(t, maximoQuadrosGIF+1, 1, 1), ErrImagemGrandeDemais},
+		{"pixels demais", gifDeclarado(0xFFFF, 0xFFFF), ErrImagemGrandeDemais},
+		{"malformado", []byte("GIF89a"), ErrImagemInvalida},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if _, erro := processarGIF(caso.conteudo, nil); !errors.Is(erro, caso.erro) {
+				t.Errorf("processarGIF() = %v, esperado %v", erro, caso.erro)
+			}
+		})
+	}
+}
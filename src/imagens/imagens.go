@@ -0,0 +1,215 @@
+package imagens
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	_ "golang.org/x/image/webp" // Decodificador de WebP
+)
+
+const (
+	// maximoPixels protege contra imagens que ocupariam memória demais ao serem decodificadas
+	maximoPixels = 40_000_000
+
+	// ladoMaximoOriginal é o maior lado da imagem guardada como original; fotos maiores são reduzidas
+	ladoMaximoOriginal = 2048
+
+	qualidadeJPEG = 85
+)
+
+// ErrImagemInvalida é retornado quando o arquivo não pode ser decodificado como JPEG, PNG, GIF ou WebP
+var ErrImagemInvalida = errors.New("o arquivo não é uma imagem válida")
+
+// ErrImagemGrandeDemais é retornado quando a imagem tem pixels demais para ser processada
+var ErrImagemGrandeDemais = errors.New("a imagem tem dimensões grandes demais")
+
+// Tamanho representa uma versão reduzida gerada para cada imagem, pelo maior lado em pixels
+type Tamanho struct {
+	Nome string
+	Lado int
+}
+
+// TamanhosPublicacao são as versões geradas para as imagens das publicações
+var TamanhosPublicacao = []Tamanho{
+	{Nome: "pequena", Lado: 160},
+	{Nome: "media", Lado: 480},
+	{Nome: "grande", Lado: 1080},
+}
+
+// TamanhosAvatar são as versões geradas para os avatares, exibidos pequenos ao lado do nome
+var TamanhosAvatar = []Tamanho{
+	{Nome: "pequena", Lado: 64},
+	{Nome: "media", Lado: 200},
+	{Nome: "grande", Lado: 400},
+}
+
+// TamanhosBanner são as versões geradas para os banners, que ocupam a largura do perfil
+var TamanhosBanner = []Tamanho{
+	{Nome: "media", Lado: 600},
+	{Nome: "grande", Lado: 1500},
+}
+
+// Versao representa a imagem já codificada em um dos tamanhos
+type Versao struct {
+	Nome     string
+	Conteudo []byte
+	Tipo     string
+	Largura  int
+	Altura   int
+}
+
+// Resultado reúne o que é gerado a partir de uma imagem enviada
+type Resultado struct {
+	Original Versao
+	Versoes  []Versao
+	Blurhash string
+}
+
+// Processar decodifica a imagem, corrige a orientação das fotos, gera o original sem metadados (EXIF, GPS,
+// comentários), as versões reduzidas nos tamanhos pedidos que forem menores que o original e o blurhash
+func Processar(conteudo []byte, tamanhos []Tamanho) (Resultado, error) {
+	configuracao, formato, erro := image.DecodeConfig(bytes.NewReader(conteudo))
+	if erro != nil {
+		return Resultado{}, ErrImagemInvalida
+	}
+
+	if configuracao.Width <= 0 || configuracao.Height <= 0 {
+		return Resultado{}, ErrImagemInvalida
+	}
+	if configuracao.Width*configuracao.Height > maximoPixels {
+		return Resultado{}, ErrImagemGrandeDemais
+	}
+
+	if formato == "gif" {
+		return processarGIF(conteudo, tamanhos)
+	}
+
+	imagem, _, erro := image.Decode(bytes.NewReader(conteudo))
+	if erro != nil {
+		return Resultado{}, ErrImagemInvalida
+	}
+
+	// Imagens com transparência continuam PNG; o resto vira JPEG, o que também descarta qualquer metadado
+	transparente := temTransparencia(imagem)
+
+	// A redução vem antes da rotação, que é feita pixel a pixel
+	original := reduzir(imagem, ladoMaximoOriginal)
+	if formato == "jpeg" {
+		original = aplicarOrientacao(original, orientacaoJPEG(conteudo))
+	}
+	resultado := Resultado{Blurhash: CodificarBlurhash(reduzir(original, 32), 4, 3)}
+
+	if resultado.Original, erro = codificar("original", original, transparente); erro != nil {
+		return Resultado{}, erro
+	}
+
+	if resultado.Versoes, erro = gerarVersoes(original, tamanhos, transparente); erro != nil {
+		return Resultado{}, erro
+	}
+
+	return resultado, nil
+}
+
+// processarGIF recodifica todos os quadros do GIF, mantendo a animação mas descartando extensões e comentários.
+// As versões reduzidas e o blurhash usam o primeiro quadro.
+func processarGIF(conteudo []byte, tamanhos []Tamanho) (Resultado, error) {
+	quadros, pixels, erro := medirGIF(conteudo)
+	if erro != nil {
+		return Resultado{}, ErrImagemInvalida
+	}
+	if quadros > maximoQuadrosGIF || pixels > maximoPixelsGIF {
+		return Resultado{}, ErrImagemGrandeDemais
+	}
+
+	animacao, erro := gif.DecodeAll(bytes.NewReader(conteudo))
+	if erro != nil || len(animacao.Image) == 0 {
+		return Resultado{}, ErrImagemInvalida
+	}
+
+	var recodificado bytes.Buffer
+	if erro = gif.EncodeAll(&recodificado, &gif.GIF{
+		Image:     animacao.Image,
+		Delay:     animacao.Delay,
+		LoopCount: animacao.LoopCount,
+		Disposal:  animacao.Disposal,
+		Config:    animacao.Config,
+	}); erro != nil {
+		return Resultado{}, erro
+	}
+
+	primeiro := image.NewRGBA(image.Rect(0, 0, animacao.Config.Width, animacao.Config.Height))
+	draw.Draw(primeiro, animacao.Image[0].Bounds(), animacao.Image[0], animacao.Image[0].Bounds().Min, draw.Over)
+
+	resultado := Resultado{
+		Original: Versao{
+			Nome:     "original",
+			Conteudo: recodificado.Bytes(),
+			Tipo:     "image/gif",
+			Largura:  animacao.Config.Width,
+			Altura:   animacao.Config.Height,
+		},
+		Blurhash: CodificarBlurhash(reduzir(primeiro, 32), 4, 3),
+	}
+
+	if resultado.Versoes, erro = gerarVersoes(primeiro, tamanhos, true); erro != nil {
+		return Resultado{}, erro
+	}
+
+	return resultado, nil
+}
+
+func gerarVersoes(original image.Image, tamanhos []Tamanho, transparente bool) ([]Versao, error) {
+	ladoOriginal := max(original.Bounds().Dx(), original.Bounds().Dy())
+
+	var versoes []Versao
+	for _, tamanho := range tamanhos {
+		if tamanho.Lado >= ladoOriginal {
+			continue
+		}
+
+		versao, erro := codificar(tamanho.Nome, reduzir(original, tamanho.Lado), transparente)
+		if erro != nil {
+			return nil, erro
+		}
+		versoes = append(versoes, versao)
+	}
+
+	return versoes, nil
+}
+
+func codificar(nome string, imagem image.Image, transparente bool) (Versao, error) {
+	versao := Versao{
+		Nome:    nome,
+		Largura: imagem.Bounds().Dx(),
+		Altura:  imagem.Bounds().Dy(),
+	}
+
+	var conteudo bytes.Buffer
+	if transparente {
+		versao.Tipo = "image/png"
+		if erro := png.Encode(&conteudo, imagem); erro != nil {
+			return Versao{}, erro
+		}
+	} else {
+		versao.Tipo = "image/jpeg"
+		if erro := jpeg.Encode(&conteudo, imagem, &jpeg.Options{Quality: qualidadeJPEG}); erro != nil {
+			return Versao{}, erro
+		}
+	}
+
+	versao.Conteudo = conteudo.Bytes()
+	return versao, nil
+}
+
+// temTransparencia verifica se algum pixel da imagem não é totalmente opaco
+func temTransparencia(imagem image.Image) bool {
+	if opaca, ok := imagem.(interface{ Opaque() bool }); ok {
+		return !opaca.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package imagens
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// orientacaoJPEG lê a tag de orientação do EXIF de um JPEG, retornando 1 (normal) se ela não existir.
+// A orientação precisa ser aplicada nos pixels, já que o EXIF é descartado ao recodificar a imagem.
+func orientacaoJPEG(conteudo []byte) int {
+	if len(conteudo) < 4 || conteudo[0] != 0xFF || conteudo[1] != 0xD8 {
+		return 1
+	}
+
+	for posicao := 2; posicao+4 <= len(conteudo); {
+		if conteudo[posicao] != 0xFF {
+			return 1
+		}
+		marcador := conteudo[posicao+1]
+		tamanho := int(binary.BigEndian.Uint16(conteudo[posicao+2:]))
+		if tamanho < 2 || posicao+2+tamanho > len(conteudo) {
+			return 1
+		}
+
+		segmento := conteudo[posicao+4 : posicao+2+tamanho]
+		if marcador == 0xE1 && len(segmento) > 6 && string(segmento[:6]) == "Exif\x00\x00" {
+			return orientacaoTIFF(segmento[6:])
+		}
+
+		// O EXIF sempre vem antes dos dados da imagem (SOS)
+		if marcador == 0xDA {
+			return 1
+		}
+		posicao += 2 + tamanho
+	}
+
+	return 1
+}
+
+func orientacaoTIFF(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var ordem binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		ordem = binary.LittleEndian
+	case "MM":
+		ordem = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(ordem.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entradas := int(ordem.Uint16(tiff[ifd:]))
+	for i := 0; i < entradas; i++ {
+		entrada := ifd + 2 + i*12
+		if entrada+12 > len(tiff) {
+			return 1
+		}
+
+		if ordem.Uint16(tiff[entrada:]) == 0x0112 {
+			orientacao := int(ordem.Uint16(tiff[entrada+8:]))
+			if orientacao < 1 || orientacao > 8 {
+				return 1
+			}
+			return orientacao
+		}
+	}
+
+	return 1
+}
+
+// aplicarOrientacao gira e espelha a imagem conforme a orientação do EXIF (valores de 1 a 8)
+func aplicarOrientacao(imagem image.Image, orientacao int) image.Image {
+	if orientacao <= 1 || orientacao > 8 {
+		return imagem
+	}
+
+	limites := imagem.Bounds()
+	largura, altura := limites.Dx(), limites.Dy()
+
+	// Orientações de 5 a 8 trocam largura e altura
+	destino := image.NewRGBA(image.Rect(0, 0, largura, altura))
+	if orientacao >= 5 {
+		destino = image.NewRGBA(image.Rect(0, 0, altura, largura))
+	}
+
+	for y := 0; y < altura; y++ {
+		for x := 0; x < largura; x++ {
+			var dx, dy int
+			switch orientacao {
+			case 2:
+				dx, dy = largura-1-x, y
+			case 3:
+				dx, dy = largura-1-x, altura-1-y
+			case 4:
+				dx, dy = x, altura-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = altura-1-y, x
+			case 7:
+				dx, dy = altura-1-y, largura-1-x
+			case 8:
+				dx, dy = y, largura-1-x
+			}
+			destino.Set(dx, dy, imagem.At(limites.Min.X+x, limites.Min.Y+y))
+		}
+	}
+
+	return destino
+}
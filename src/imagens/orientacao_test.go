@@ -0,0 +1,138 @@
+package imagens
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// jpegComOrientacao monta o começo de um JPEG (SOI, um APP0 qualquer, o APP1 com o EXIF e o SOS) cujo IFD tem
+// só a tag de orientação. Os dados da imagem não importam para orientacaoJPEG.
+func jpegComOrientacao(ordem binary.ByteOrder, orientacao uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if ordem == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	ordem.PutUint16(tiff[2:], 42)
+	ordem.PutUint32(tiff[4:], 8)
+	ordem.PutUint16(tiff[8:], 1)
+	ordem.PutUint16(tiff[10:], 0x0112)
+	ordem.PutUint16(tiff[12:], 3)
+	ordem.PutUint32(tiff[14:], 1)
+	ordem.PutUint16(tiff[18:], orientacao)
+
+	return montarJPEG(append([]byte("Exif\x00\x00"), tiff...))
+}
+
+func montarJPEG(exif []byte) []byte {
+	conteudo := []byte{0xFF, 0xD8}
+	conteudo = append(conteudo, 0xFF, 0xE0, 0x00, 0x07, 'J', 'F', 'I', 'F', 0x00)
+	conteudo = append(conteudo, 0xFF, 0xE1)
+	conteudo = binary.BigEndian.AppendUint16(conteudo, uint16(2+len(exif)))
+	conteudo = append(conteudo, exif...)
+	return append(conteudo, 0xFF, 0xDA, 0x00, 0x02)
+}
+
+func TestOrientacaoJPEG(t *testing.T) {
+	for orientacao := uint16(1); orientacao <= 8; orientacao++ {
+		for _, ordem := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			if obtida := orientacaoJPEG(jpegComOrientacao(ordem, orientacao)); obtida != int(orientacao) {
+				t.Errorf("orientacaoJPEG(%s, %d) = %d", ordem, orientacao, obtida)
+			}
+		}
+	}
+}
+
+func TestOrientacaoJPEGMalformado(t *testing.T) {
+	valido := jpegComOrientacao(binary.BigEndian, 6)
+	inicioTIFF := len(valido) - 4 - 26
+
+	alterar := func(alteracao func(conteudo []byte)) []byte {
+		conteudo := append([]byte(nil), valido...)
+		alteracao(conteudo)
+		return conteudo
+	}
+
+	casos := []struct {
+		nome     string
+		conteudo []byte
+	}{
+		{"vazio", nil},
+		{"não é JPEG", []byte("\x89PNG\r\n\x1a\n")},
+		{"só o SOI", []byte{0xFF, 0xD8}},
+		{"sem EXIF", montarJPEG(nil)[:13]},
+		{"EXIF sem TIFF", montarJPEG([]byte("Exif\x00\x00"))},
+		{"orientação zero", jpegComOrientacao(binary.BigEndian, 0)},
+		{"orientação nove", jpegComOrientacao(binary.BigEndian, 9)},
+		{"ordem de bytes desconhecida", alterar(func(c []byte) { copy(c[inicioTIFF:], "XX") })},
+		{"segmento menor que o cabeçalho", alterar(func(c []byte) { c[13], c[14] = 0x00, 0x01 })},
+		{"segmento maior que o arquivo", alterar(func(c []byte) { c[13], c[14] = 0xFF, 0xFF })},
+		{"marcador inválido", alterar(func(c []byte) { c[11] = 0x00 })},
+		{"IFD fora do TIFF", alterar(func(c []byte) { binary.BigEndian.PutUint32(c[inicioTIFF+4:], 0xFFFFFFFF) })},
+		{"entradas além do TIFF", alterar(func(c []byte) {
+			binary.BigEndian.PutUint16(c[inicioTIFF+8:], 0xFFFF)
+			binary.BigEndian.PutUint16(c[inicioTIFF+10:], 0x0100)
+		})},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if obtida := orientacaoJPEG(caso.conteudo); obtida != 1 {
+				t.Errorf("orientacaoJPEG() = %d, esperado 1", obtida)
+			}
+		})
+	}
+
+	// Um arquivo cortado em qualquer ponto não pode derrubar a leitura
+	for tamanho := range valido {
+		if obtida := orientacaoJPEG(valido[:tamanho]); obtida != 1 && obtida != 6 {
+			t.Errorf("orientacaoJPEG(cortado em %d) = %d", tamanho, obtida)
+		}
+	}
+}
+
+func TestAplicarOrientacao(t *testing.T) {
+	// Imagem 2x3 em que só os dois pixels da primeira linha são marcados
+	var (
+		vermelho = color.RGBA{R: 255, A: 255}
+		azul     = color.RGBA{B: 255, A: 255}
+	)
+	original := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	original.Set(0, 0, vermelho)
+	original.Set(1, 0, azul)
+
+	casos := []struct {
+		orientacao      int
+		largura, altura int
+		vermelho, azul  image.Point
+	}{
+		{1, 2, 3, image.Pt(0, 0), image.Pt(1, 0)},
+		{2, 2, 3, image.Pt(1, 0), image.Pt(0, 0)}, // espelhada na horizontal
+		{3, 2, 3, image.Pt(1, 2), image.Pt(0, 2)}, // girada 180°
+		{4, 2, 3, image.Pt(0, 2), image.Pt(1, 2)}, // espelhada na vertical
+		{5, 3, 2, image.Pt(0, 0), image.Pt(0, 1)}, // transposta
+		{6, 3, 2, image.Pt(2, 0), image.Pt(2, 1)}, // girada 90° no sentido horário
+		{7, 3, 2, image.Pt(2, 1), image.Pt(2, 0)}, // transversa
+		{8, 3, 2, image.Pt(0, 1), image.Pt(0, 0)}, // girada 90° no sentido anti-horário
+		{0, 2, 3, image.Pt(0, 0), image.Pt(1, 0)},
+		{9, 2, 3, image.Pt(0, 0), image.Pt(1, 0)},
+	}
+
+	for _, caso := range casos {
+		girada := aplicarOrientacao(original, caso.orientacao)
+		if limites := girada.Bounds(); limites.Dx() != caso.largura || limites.Dy() != caso.altura {
+			t.Errorf("orientação %d: tamanho %dx%d, esperado %dx%d",
+				caso.orientacao, limites.Dx(), limites.Dy(), caso.largura, caso.altura)
+			continue
+		}
+		if cor := color.RGBAModel.Convert(girada.At(caso.vermelho.X, caso.vermelho.Y)); cor != vermelho {
+			t.Errorf("orientação %d: pixel vermelho não está em %v", caso.orientacao, caso.vermelho)
+		}
+		if cor := color.RGBAModel.Convert(girada.At(caso.azul.X, caso.azul.Y)); cor != azul {
+			t.Errorf("orientação %d: pixel azul não está em %v", caso.orientacao, caso.azul)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package imagens
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// reduzir diminui a imagem para que o maior lado tenha no máximo o tamanho informado, mantendo a proporção.
+// Imagens que já cabem nesse tamanho são apenas copiadas para RGBA.
+func reduzir(imagem image.Image, ladoMaximo int) image.Image {
+	limites := imagem.Bounds()
+	largura, altura := limites.Dx(), limites.Dy()
+
+	if largura > ladoMaximo || altura > ladoMaximo {
+		if largura >= altura {
+			altura = max(1, altura*ladoMaximo/largura)
+			largura = ladoMaximo
+		} else {
+			largura = max(1, largura*ladoMaximo/altura)
+			altura = ladoMaximo
+		}
+	}
+
+	destino := image.NewRGBA(image.Rect(0, 0, largura, altura))
+	if largura == limites.Dx() && altura == limites.Dy() {
+		draw.Copy(destino, image.Point{}, imagem, limites, draw.Src, nil)
+	} else {
+		draw.CatmullRom.Scale(destino, destino.Bounds(), imagem, limites, draw.Src, nil)
+	}
+
+	return destino
+}
@@ -2,7 +2,10 @@ package middlewares
 
 import (
 	"api/src/autenticacao"
+	"api/src/banco"
+	"api/src/repository"
 	"api/src/respostas"
+	"errors"
 	"log"
 	"net/http"
 )
@@ -15,13 +18,44 @@ func Logger(proximaFuncao http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// Autenticar verifica se o usuário fazendo a requisição está autenticado
+// Autenticar verifica se o usuário fazendo a requisição está autenticado. A sessão é conferida no banco a cada
+// requisição, para que uma conta suspensa ou uma sessão encerrada percam o acesso na hora, e não só quando o token
+// expirar. A mesma consulta traz o papel do usuário, usado depois por Autorizar e pelos controllers. Ela usa o pool
+// compartilhado, sem abrir uma conexão nova por requisição.
 func Autenticar(proximaFuncao http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if erro := autenticacao.ValidarToken(r); erro != nil {
+		usuarioID, versaoSessao, erro := autenticacao.ExtrairSessao(r)
+		if erro != nil {
 			respostas.Erro(w, http.StatusUnauthorized, erro)
 			return
 		}
+
+		db, erro := banco.Compartilhada()
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+		usuario, erro := repository.NovoRepositorioDeUsuarios(db).BuscarSessao(usuarioID)
+		if erro != nil {
+			respostas.Erro(w, http.StatusInternalServerError, erro)
+			return
+		}
+
+		if statusCode, erro := autenticacao.ConferirSessao(usuario, versaoSessao); erro != nil {
+			respostas.Erro(w, statusCode, erro)
+			return
+		}
+		proximaFuncao(w, autenticacao.ComPapel(r, usuario.Papel))
+	}
+}
+
+// Autorizar verifica se o usuário autenticado possui algum dos papéis exigidos pela rota
+func Autorizar(papeis []string, proximaFuncao http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !autenticacao.PossuiPapel(r, papeis...) {
+			respostas.Erro(w, http.StatusForbidden, errors.New("Você não tem permissão para acessar este recurso."))
+			return
+		}
 		proximaFuncao(w, r)
 	}
 }
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaximoParticipantesConversa é o tamanho máximo de uma conversa em grupo, contando quem a criou
+const MaximoParticipantesConversa = 10
+
+// Conversa representa uma conversa privada entre dois ou mais usuários
+type Conversa struct {
+	ID             uint64                 `json:"id,omitempty"`
+	Participantes  []ParticipanteConversa `json:"participantes,omitempty"`
+	UltimaMensagem *Mensagem              `json:"ultimaMensagem,omitempty"`
+	NaoLidas       uint64                 `json:"naoLidas"`
+	CriadaEm       time.Time              `json:"criadaEm,omitempty"`
+	AtualizadaEm   time.Time              `json:"atualizadaEm,omitempty"`
+}
+
+// ParticipanteConversa representa um usuário que participa de uma conversa
+type ParticipanteConversa struct {
+	UsuarioID uint64 `json:"usuarioId"`
+	Nick      string `json:"nick"`
+}
+
+// NovaConversa representa o formato da requisição que inicia uma conversa
+type NovaConversa struct {
+	Participantes []uint64 `json:"participantes"`
+}
+
+// Mensagem representa uma mensagem enviada em uma conversa
+type Mensagem struct {
+	ID            uint64    `json:"id,omitempty"`
+	ConversaID    uint64    `json:"conversaId,omitempty"`
+	RemetenteID   uint64    `json:"remetenteId,omitempty"`
+	RemetenteNick string    `json:"remetenteNick,omitempty"`
+	Conteudo      string    `json:"conteudo,omitempty"`
+	CriadaEm      time.Time `json:"criadaEm,omitempty"`
+}
+
+// Preparar remove participantes repetidos (e o próprio criador) e valida o tamanho da conversa
+func (conversa *NovaConversa) Preparar(criadorID uint64) error {
+	vistos := map[uint64]bool{criadorID: true}
+	var participantes []uint64
+
+	for _, participanteID := range conversa.Participantes {
+		if participanteID == 0 || vistos[participanteID] {
+			continue
+		}
+		vistos[participanteID] = true
+		participantes = append(participantes, participanteID)
+	}
+
+	if len(participantes) == 0 {
+		return errors.New("informe ao menos um participante além de você")
+	}
+
+	if len(participantes)+1 > MaximoParticipantesConversa {
+		return errors.New("a conversa excede o número máximo de participantes")
+	}
+
+	conversa.Participantes = participantes
+	return nil
+}
+
+// Preparar vai validar e formatar a mensagem recebida
+func (mensagem *Mensagem) Preparar() error {
+	mensagem.Conteudo = strings.TrimSpace(mensagem.Conteudo)
+
+	if mensagem.Conteudo == "" {
+		return errors.New("o conteúdo da mensagem não pode estar em branco")
+	}
+
+	if len([]rune(mensagem.Conteudo)) > 1000 {
+		return errors.New("a mensagem deve ter no máximo 1000 caracteres")
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Motivos aceitos em uma denúncia
+const (
+	MotivoSpam            = "spam"
+	MotivoAssedio         = "assedio"
+	MotivoDiscursoDeOdio  = "discurso_de_odio"
+	MotivoViolencia       = "violencia"
+	MotivoNudez           = "nudez"
+	MotivoInformacaoFalsa = "informacao_falsa"
+	MotivoOutro           = "outro"
+)
+
+// Situações de uma denúncia na fila de moderação
+const (
+	StatusDenunciaAberta    = "aberta"
+	StatusDenunciaEmAnalise = "em_analise"
+	StatusDenunciaResolvida = "resolvida"
+)
+
+// Resoluções possíveis para uma denúncia
+const (
+	ResolucaoDescartada         = "descartada"
+	ResolucaoPublicacaoRemovida = "publicacao_removida"
+	ResolucaoAutorSuspenso      = "autor_suspenso"
+)
+
+// Denuncia representa uma denúncia de publicação ou de usuário feita para a moderação
+type Denuncia struct {
+	ID                  uint64     `json:"id,omitempty"`
+	DenuncianteID       uint64     `json:"denuncianteId,omitempty"`
+	UsuarioDenunciadoID uint64     `json:"usuarioDenunciadoId,omitempty"`
+	PublicacaoID        uint64     `json:"publicacaoId,omitempty"`
+	Motivo              string     `json:"motivo,omitempty"`
+	Descricao           string     `json:"descricao,omitempty"`
+	Status              string     `json:"status,omitempty"`
+	ModeradorID         uint64     `json:"moderadorId,omitempty"`
+	Resolucao           string     `json:"resolucao,omitempty"`
+	Observacao          string     `json:"observacao,omitempty"`
+	CriadaEm            time.Time  `json:"criadaEm,omitempty"`
+	ResolvidaEm         *time.Time `json:"resolvidaEm,omitempty"`
+}
+
+// ResolucaoDenuncia representa o formato da requisição que resolve uma denúncia
+type ResolucaoDenuncia struct {
+	Resolucao  string `json:"resolucao"`
+	Observacao string `json:"observacao"`
+}
+
+// HistoricoDenuncia representa uma ação registrada na auditoria de uma denúncia
+type HistoricoDenuncia struct {
+	ID          uint64    `json:"id,omitempty"`
+	DenunciaID  uint64    `json:"denunciaId,omitempty"`
+	ModeradorID uint64    `json:"moderadorId,omitempty"`
+	Acao        string    `json:"acao,omitempty"`
+	Observacao  string    `json:"observacao,omitempty"`
+	CriadoEm    time.Time `json:"criadoEm,omitempty"`
+}
+
+// Preparar vai validar e formatar a denúncia recebida
+func (denuncia *Denuncia) Preparar() error {
+	denuncia.Motivo = strings.TrimSpace(denuncia.Motivo)
+	denuncia.Descricao = strings.TrimSpace(denuncia.Descricao)
+
+	switch denuncia.Motivo {
+	case MotivoSpam, MotivoAssedio, MotivoDiscursoDeOdio, MotivoViolencia,
+		MotivoNudez, MotivoInformacaoFalsa, MotivoOutro:
+	default:
+		return errors.New("o motivo da denúncia é inválido")
+	}
+
+	if denuncia.Motivo == MotivoOutro && denuncia.Descricao == "" {
+		return errors.New("descreva o motivo da denúncia")
+	}
+
+	if len([]rune(denuncia.Descricao)) > 500 {
+		return errors.New("a descrição da denúncia deve ter no máximo 500 caracteres")
+	}
+
+	return nil
+}
+
+// Preparar vai validar e formatar a resolução recebida
+func (resolucao *ResolucaoDenuncia) Preparar() error {
+	resolucao.Resolucao = strings.TrimSpace(resolucao.Resolucao)
+	resolucao.Observacao = strings.TrimSpace(resolucao.Observacao)
+
+	switch resolucao.Resolucao {
+	case ResolucaoDescartada, ResolucaoPublicacaoRemovida, ResolucaoAutorSuspenso:
+	default:
+		return errors.New("a resolução da denúncia é inválida")
+	}
+
+	if len([]rune(resolucao.Observacao)) > 500 {
+		return errors.New("a observação deve ter no máximo 500 caracteres")
+	}
+
+	return nil
+}
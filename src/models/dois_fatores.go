@@ -0,0 +1,29 @@
+package models
+
+// MaximoFalhasDoisFatores é quantos códigos errados seguidos bloqueiam o login com dois fatores por um tempo
+const MaximoFalhasDoisFatores = 5
+
+// DoisFatores representa a configuração de autenticação em dois fatores de um usuário
+type DoisFatores struct {
+	UsuarioID   uint64 `json:"-"`
+	Segredo     string `json:"segredo,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	Ativo       bool   `json:"ativo"`
+	UltimoPasso int64  `json:"-"`
+
+	// Bloqueado indica que o limite de falhas foi atingido há pouco. VersaoToken muda a cada bloqueio e invalida
+	// os tokens de MFA pendente emitidos antes dele.
+	Bloqueado   bool   `json:"-"`
+	VersaoToken uint64 `json:"-"`
+}
+
+// CodigoDoisFatores representa o formato da requisição que envia um código do app autenticador
+type CodigoDoisFatores struct {
+	Codigo string `json:"codigo"`
+}
+
+// LoginDoisFatores representa o formato da requisição que conclui um login com dois fatores
+type LoginDoisFatores struct {
+	Token  string `json:"token"`
+	Codigo string `json:"codigo"`
+}
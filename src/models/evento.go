@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipos de evento de domínio gravados na outbox
+const (
+	EventoUsuarioCriado           = "usuario.criado"
+	EventoUsuarioSeguido          = "usuario.seguido"
+	EventoUsuarioSeguirSolicitado = "usuario.seguir_solicitado"
+	EventoPublicacaoCriada        = "publicacao.criada"
+	EventoPublicacaoEditada       = "publicacao.editada"
+	EventoPublicacaoExcluida      = "publicacao.excluida"
+	EventoPublicacaoCurtida       = "publicacao.curtida"
+	EventoMensagemEnviada         = "mensagem.enviada"
+	EventoMidiasDescartadas       = "midias.descartadas"
+)
+
+// Situações de um evento na outbox
+const (
+	StatusEventoPendente   = "pendente"
+	StatusEventoProcessado = "processado"
+	StatusEventoFalhou     = "falhou"
+)
+
+// EventoDominio representa algo que aconteceu na rede social, gravado na outbox junto com a escrita
+// que o gerou e entregue depois aos manipuladores registrados
+type EventoDominio struct {
+	ID         uint64          `json:"id"`
+	Tipo       string          `json:"tipo"`
+	Dados      json.RawMessage `json:"dados"`
+	Tentativas uint64          `json:"tentativas"`
+	Concluidos []string        `json:"concluidos,omitempty"`
+	CriadoEm   time.Time       `json:"criadoEm"`
+}
+
+// Curtida representa um usuário curtindo uma publicação
+type Curtida struct {
+	PublicacaoID uint64 `json:"publicacaoId"`
+	AutorID      uint64 `json:"autorId"`
+	UsuarioID    uint64 `json:"usuarioId"`
+}
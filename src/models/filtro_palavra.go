@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// FiltroPalavra representa uma palavra que o usuário não quer ver no feed
+type FiltroPalavra struct {
+	ID       uint64     `json:"id,omitempty"`
+	Palavra  string     `json:"palavra,omitempty"`
+	ExpiraEm *time.Time `json:"expiraEm,omitempty"`
+	CriadoEm time.Time  `json:"criadoEm,omitempty"`
+}
+
+// Preparar vai validar e formatar o filtro recebido. A palavra é guardada sem acentos, na mesma forma
+// em que o texto das publicações é comparado.
+func (filtro *FiltroPalavra) Preparar() error {
+	filtro.Palavra = SemAcento(strings.TrimSpace(filtro.Palavra))
+
+	if filtro.Palavra == "" {
+		return errors.New("a palavra do filtro não pode estar em branco")
+	}
+
+	if len([]rune(filtro.Palavra)) > 100 {
+		return errors.New("a palavra do filtro deve ter no máximo 100 caracteres")
+	}
+
+	if filtro.ExpiraEm != nil && !filtro.ExpiraEm.After(time.Now()) {
+		return errors.New("a data de expiração precisa estar no futuro")
+	}
+
+	return nil
+}
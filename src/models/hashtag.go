@@ -0,0 +1,71 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TamanhoMaximoHashtag é o maior número de caracteres aceito em uma hashtag; as maiores são ignoradas
+const TamanhoMaximoHashtag = 50
+
+// As marcas combinantes (\p{M}) fazem parte da hashtag para que uma letra decomposta que não tem forma
+// composta não a corte no meio
+var regexHashtag = regexp.MustCompile(`(?:^|[^\p{L}\p{M}\p{N}_&#/])#([\p{L}\p{M}\p{N}_]+)`)
+
+// Hashtag representa uma hashtag em alta, com quantas publicações e autores a usaram na janela de tempo
+type Hashtag struct {
+	Nome        string `json:"nome"`
+	Publicacoes uint64 `json:"publicacoes"`
+	Autores     uint64 `json:"autores"`
+}
+
+// NormalizarHashtag deixa a hashtag em minúsculas e sem acentos, para que #Eleição, #eleicao e #ELEIÇÃO
+// sejam a mesma. O # do começo, se houver, é removido.
+func NormalizarHashtag(hashtag string) string {
+	return SemAcento(strings.TrimPrefix(strings.TrimSpace(hashtag), "#"))
+}
+
+// HashtagValida informa se o texto, já normalizado, pode ser uma hashtag: letras, números e _,
+// com pelo menos uma letra (#2024 não é hashtag)
+func HashtagValida(hashtag string) bool {
+	if hashtag == "" || utf8.RuneCountInString(hashtag) > TamanhoMaximoHashtag {
+		return false
+	}
+
+	temLetra := false
+	for _, letra := range hashtag {
+		switch {
+		case unicode.IsLetter(letra):
+			temLetra = true
+		case unicode.IsDigit(letra) || letra == '_':
+		default:
+			return false
+		}
+	}
+
+	return temLetra
+}
+
+// Hashtags retorna as hashtags normalizadas do título e do conteúdo da publicação, sem repetição
+func (publicacao Publicacao) Hashtags() []string {
+	var (
+		hashtags []string
+		vistas   = make(map[string]bool)
+	)
+	for _, texto := range []string{publicacao.Titulo, publicacao.Conteudo} {
+		for _, ocorrencia := range regexHashtag.FindAllStringSubmatch(norm.NFC.String(texto), -1) {
+			hashtag := NormalizarHashtag(ocorrencia[1])
+			if !HashtagValida(hashtag) || vistas[hashtag] {
+				continue
+			}
+			vistas[hashtag] = true
+			hashtags = append(hashtags, hashtag)
+		}
+	}
+
+	return hashtags
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizarHashtag(t *testing.T) {
+	casos := []struct {
+		nome     string
+		hashtag  string
+		esperado string
+	}{
+		{"composta", "#Eleição", "eleicao"},
+		{"decomposta", "#Eleic\u0327a\u0303o", "eleicao"},
+		{"maiúsculas e espaços", "  #ELEIÇÃO ", "eleicao"},
+		{"sem #", "eleicao", "eleicao"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if obtida := NormalizarHashtag(caso.hashtag); obtida != caso.esperado {
+				t.Errorf("NormalizarHashtag(%q) = %q, esperado %q", caso.hashtag, obtida, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestHashtags(t *testing.T) {
+	casos := []struct {
+		nome     string
+		titulo   string
+		conteudo string
+		esperado []string
+	}{
+		{"composta", "", "Resultado da #Eleição", []string{"eleicao"}},
+		{"decomposta", "", "Resultado da #Eleic\u0327a\u0303o", []string{"eleicao"}},
+		{"composta e decomposta são a mesma", "#Eleição", "#Eleic\u0327a\u0303o e #eleicao", []string{"eleicao"}},
+		{"só números", "", "Feliz #2024", nil},
+		{"números com letra", "", "#eleicao2024", []string{"eleicao2024"}},
+		{"50 caracteres", "", "#" + strings.Repeat("a", 50), []string{strings.Repeat("a", 50)}},
+		{"51 caracteres", "", "#" + strings.Repeat("a", 51), nil},
+		{"51 caracteres acentuados", "", "#" + strings.Repeat("ã", 51), nil},
+		{"entidade HTML", "", "a&#tag", nil},
+		{"colada em uma palavra", "", "a#tag", nil},
+		{"âncora de URL", "", "https://exemplo.com/#tag", nil},
+		{"pontuação depois", "", "(#tag), #outra.", []string{"tag", "outra"}},
+		{"repetida", "#tag", "#tag #outra #TAG", []string{"tag", "outra"}},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			publicacao := Publicacao{Titulo: caso.titulo, Conteudo: caso.conteudo}
+			if obtidas := publicacao.Hashtags(); !reflect.DeepEqual(obtidas, caso.esperado) {
+				t.Errorf("Hashtags() = %q, esperado %q", obtidas, caso.esperado)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// TiposDeImagemAceitos são os formatos de imagem que podem ser anexados a uma publicação, com a extensão usada no arquivo
+var TiposDeImagemAceitos = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Midia representa um arquivo anexado a uma publicação
+type Midia struct {
+	ID        uint64          `json:"id,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	Tipo      string          `json:"tipo,omitempty"`
+	Tamanho   int64           `json:"tamanho,omitempty"`
+	Largura   int             `json:"largura,omitempty"`
+	Altura    int             `json:"altura,omitempty"`
+	Blurhash  string          `json:"blurhash,omitempty"`
+	Variantes []VarianteMidia `json:"variantes,omitempty"`
+	Chave     string          `json:"-"`
+	CriadaEm  time.Time       `json:"-"`
+}
+
+// VarianteMidia representa uma versão reduzida de uma imagem, para que os clientes escolham o tamanho que vão exibir
+type VarianteMidia struct {
+	Nome    string `json:"nome"`
+	URL     string `json:"url"`
+	Largura int    `json:"largura"`
+	Altura  int    `json:"altura"`
+	Chave   string `json:"-"`
+}
+
+// Chaves retorna a chave do arquivo original e as de todas as suas variantes
+func (midia Midia) Chaves() []string {
+	chaves := []string{midia.Chave}
+	for _, variante := range midia.Variantes {
+		chaves = append(chaves, variante.Chave)
+	}
+	return chaves
+}
+
+// PublicacaoExcluida é o evento de uma publicação excluída, com as chaves dos arquivos que precisam ser apagados
+type PublicacaoExcluida struct {
+	Publicacao
+	ChavesMidias []string `json:"chavesMidias,omitempty"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tipos de notificação
+const (
+	NotificacaoSeguidor          = "seguidor"
+	NotificacaoCurtida           = "curtida"
+	NotificacaoMencao            = "mencao"
+	NotificacaoSolicitacaoSeguir = "solicitacao_seguir"
+)
+
+// Notificacao representa uma notificação (ou um grupo de notificações agregadas) de um usuário
+type Notificacao struct {
+	ID           uint64            `json:"id,omitempty"`
+	Tipo         string            `json:"tipo,omitempty"`
+	PublicacaoID uint64            `json:"publicacaoId,omitempty"`
+	Atores       []AtorNotificacao `json:"atores,omitempty"`
+	TotalAtores  uint64            `json:"totalAtores"`
+	Texto        string            `json:"texto,omitempty"`
+	Lida         bool              `json:"lida"`
+	CriadaEm     time.Time         `json:"criadaEm,omitempty"`
+}
+
+// AtorNotificacao representa um usuário que gerou uma notificação
+type AtorNotificacao struct {
+	UsuarioID uint64 `json:"usuarioId"`
+	Nick      string `json:"nick"`
+}
+
+// PaginaNotificacoes representa uma página de notificações junto com o total de não lidas
+type PaginaNotificacoes struct {
+	NaoLidas     uint64        `json:"naoLidas"`
+	Notificacoes []Notificacao `json:"notificacoes"`
+}
+
+// GerarTexto monta a frase exibida para a notificação a partir dos atores
+func (notificacao *Notificacao) GerarTexto() {
+	if len(notificacao.Atores) == 0 {
+		return
+	}
+
+	quem := notificacao.Atores[0].Nick
+	if notificacao.TotalAtores == 2 && len(notificacao.Atores) > 1 {
+		quem = fmt.Sprintf("%s e %s", quem, notificacao.Atores[1].Nick)
+	} else if notificacao.TotalAtores > 1 {
+		outros := notificacao.TotalAtores - 1
+		pessoas := "pessoas"
+		if outros == 1 {
+			pessoas = "pessoa"
+		}
+		quem = fmt.Sprintf("%s e mais %d %s", quem, outros, pessoas)
+	}
+
+	plural := notificacao.TotalAtores > 1
+	switch notificacao.Tipo {
+	case NotificacaoSeguidor:
+		notificacao.Texto = quem + escolher(plural, " começaram", " começou") + " a seguir você"
+	case NotificacaoCurtida:
+		notificacao.Texto = quem + escolher(plural, " curtiram", " curtiu") + " sua publicação"
+	case NotificacaoMencao:
+		notificacao.Texto = quem + " mencionou você em uma publicação"
+	case NotificacaoSolicitacaoSeguir:
+		notificacao.Texto = quem + " pediu para seguir você"
+	}
+}
+
+func escolher(condicao bool, seVerdadeiro, seFalso string) string {
+	if condicao {
+		return seVerdadeiro
+	}
+	return seFalso
+}
@@ -2,10 +2,25 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+// MaximoMencoes é quantos usuários diferentes uma publicação pode mencionar
+const MaximoMencoes = 10
+
+// Campos da publicação onde uma menção pode aparecer
+const (
+	CampoTitulo   = "titulo"
+	CampoConteudo = "conteudo"
+)
+
+var regexMencao = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@])@([A-Za-z0-9_.]+)`)
+
 // Publicacao representa uma publicação feita por um usuário
 type Publicacao struct {
 	ID        uint64    `json:"id,omitempty"`
@@ -15,6 +30,52 @@ type Publicacao struct {
 	AutorNick string    `json:"autorNick,omitempty"`
 	Curtidas  uint64    `json:"curtidas"`
 	CriadaEm  time.Time `json:"criadaEm,omitempty"`
+	Midias    []Midia   `json:"midias,omitempty"`
+	Mencoes   []Mencao  `json:"mencoes,omitempty"`
+
+	Republicacoes  uint64        `json:"republicacoes"`
+	RepublicadaPor *Republicacao `json:"republicadaPor,omitempty"`
+
+	CitadaID uint64            `json:"citadaId,omitempty"`
+	Citada   *PublicacaoCitada `json:"citada,omitempty"`
+}
+
+// PublicacaoCitada é a publicação que uma citação comenta. Se o original foi excluído ou quem está vendo não
+// pode mais vê-lo (bloqueio, silenciamento ou perfil privado), só o ID vem preenchido e Disponivel fica falso.
+type PublicacaoCitada struct {
+	ID         uint64     `json:"id"`
+	Disponivel bool       `json:"disponivel"`
+	Titulo     string     `json:"titulo,omitempty"`
+	Conteudo   string     `json:"conteudo,omitempty"`
+	AutorID    uint64     `json:"autorId,omitempty"`
+	AutorNick  string     `json:"autorNick,omitempty"`
+	CriadaEm   *time.Time `json:"criadaEm,omitempty"`
+}
+
+// Republicacao indica, no feed, quem compartilhou a publicação com os seus seguidores e quando
+type Republicacao struct {
+	UsuarioID     uint64    `json:"usuarioId"`
+	Nick          string    `json:"nick"`
+	RepublicadaEm time.Time `json:"republicadaEm"`
+}
+
+// PublicacaoEncontrada representa uma publicação no resultado da busca, com os trechos onde os termos aparecem
+// marcados com <mark></mark>. O texto dos trechos já vem escapado para HTML.
+type PublicacaoEncontrada struct {
+	Publicacao
+	TituloDestacado string  `json:"tituloDestacado"`
+	Trecho          string  `json:"trecho"`
+	Relevancia      float64 `json:"relevancia"`
+}
+
+// Mencao representa um @nick no título ou no conteúdo que corresponde a um usuário. Inicio e Fim são as posições,
+// em caracteres (code points), do @ e do primeiro caractere depois do nick no campo indicado.
+type Mencao struct {
+	UsuarioID uint64 `json:"usuarioId"`
+	Nick      string `json:"nick"`
+	Campo     string `json:"campo"`
+	Inicio    int    `json:"inicio"`
+	Fim       int    `json:"fim"`
 }
 
 // Preparar vai chamar os métodos para validar e formatar a publicação recebida
@@ -35,6 +96,10 @@ func (publicacao *Publicacao) Validar() error {
 		return errors.New("o conteúdo da publicação não pode estar em branco")
 	}
 
+	if len(publicacao.NicksMencionados()) > MaximoMencoes {
+		return fmt.Errorf("a publicação pode mencionar no máximo %d usuários", MaximoMencoes)
+	}
+
 	return nil
 }
 
@@ -42,3 +107,65 @@ func (publicacao *Publicacao) formatar() {
 	publicacao.Titulo = strings.TrimSpace(publicacao.Titulo)
 	publicacao.Conteudo = strings.TrimSpace(publicacao.Conteudo)
 }
+
+// ExtrairMencoes encontra cada @nick do título e do conteúdo com a sua posição. Os usuários ainda não são
+// resolvidos: UsuarioID fica zerado e é o repositório que descarta os nicks que não existem.
+func (publicacao Publicacao) ExtrairMencoes() []Mencao {
+	var mencoes []Mencao
+	for _, campo := range []struct{ nome, texto string }{
+		{CampoTitulo, publicacao.Titulo},
+		{CampoConteudo, publicacao.Conteudo},
+	} {
+		for _, posicoes := range regexMencao.FindAllStringSubmatchIndex(campo.texto, -1) {
+			// Um @ seguido de uma letra acentuada (@joão) não é uma menção a @jo
+			if proxima, _ := utf8.DecodeRuneInString(campo.texto[posicoes[3]:]); unicode.IsLetter(proxima) || unicode.IsDigit(proxima) {
+				continue
+			}
+
+			// Um ponto no fim do nick é pontuação da frase, e não parte do nick
+			nick := strings.TrimRight(campo.texto[posicoes[2]:posicoes[3]], ".")
+			if nick == "" {
+				continue
+			}
+
+			inicio := utf8.RuneCountInString(campo.texto[:posicoes[2]-1])
+			mencoes = append(mencoes, Mencao{
+				Nick:   nick,
+				Campo:  campo.nome,
+				Inicio: inicio,
+				Fim:    inicio + 1 + utf8.RuneCountInString(nick),
+			})
+		}
+	}
+
+	return mencoes
+}
+
+// NicksMencionados retorna os nicks mencionados com @ no título e no conteúdo, sem repetição
+func (publicacao Publicacao) NicksMencionados() []string {
+	var (
+		nicks  []string
+		vistos = make(map[string]bool)
+	)
+	for _, mencao := range publicacao.ExtrairMencoes() {
+		if vistos[mencao.Nick] {
+			continue
+		}
+		vistos[mencao.Nick] = true
+		nicks = append(nicks, mencao.Nick)
+	}
+
+	return nicks
+}
+
+// ContemAlgumaPalavra informa se o título ou o conteúdo contém alguma das palavras filtradas, com a mesma regra
+// dos filtros de palavras do feed
+func (publicacao Publicacao) ContemAlgumaPalavra(palavras []string) bool {
+	for _, palavra := range palavras {
+		if ContemPalavra(publicacao.Titulo, palavra) || ContemPalavra(publicacao.Conteudo, palavra) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtrairMencoes(t *testing.T) {
+	casos := []struct {
+		nome     string
+		titulo   string
+		conteudo string
+		esperado []Mencao
+	}{
+		{"começo do texto", "", "@ana oi", []Mencao{
+			{Nick: "ana", Campo: CampoConteudo, Inicio: 0, Fim: 4},
+		}},
+		{"texto multibyte antes", "", "Olá, ação! @ana", []Mencao{
+			{Nick: "ana", Campo: CampoConteudo, Inicio: 11, Fim: 15},
+		}},
+		{"emoji antes", "", "🎉🎉 @ana", []Mencao{
+			{Nick: "ana", Campo: CampoConteudo, Inicio: 3, Fim: 7},
+		}},
+		{"ponto no fim da frase", "", "Falei com @ana.", []Mencao{
+			{Nick: "ana", Campo: CampoConteudo, Inicio: 10, Fim: 14},
+		}},
+		{"ponto no meio do nick", "", "oi @ana.silva!", []Mencao{
+			{Nick: "ana.silva", Campo: CampoConteudo, Inicio: 3, Fim: 13},
+		}},
+		{"parecido com email", "", "a@b", nil},
+		{"email", "", "escreva para ana@exemplo.com", nil},
+		{"letra acentuada depois", "", "oi @joão", nil},
+		{"só o @", "", "oi @ e tchau", nil},
+		{"nick repetido", "", "@ana e @ana", []Mencao{
+			{Nick: "ana", Campo: CampoConteudo, Inicio: 0, Fim: 4},
+			{Nick: "ana", Campo: CampoConteudo, Inicio: 7, Fim: 11},
+		}},
+		{"título e conteúdo", "Para @ana", "e @bia", []Mencao{
+			{Nick: "ana", Campo: CampoTitulo, Inicio: 5, Fim: 9},
+			{Nick: "bia", Campo: CampoConteudo, Inicio: 2, Fim: 6},
+		}},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			publicacao := Publicacao{Titulo: caso.titulo, Conteudo: caso.conteudo}
+			if obtidas := publicacao.ExtrairMencoes(); !reflect.DeepEqual(obtidas, caso.esperado) {
+				t.Errorf("ExtrairMencoes() = %+v, esperado %+v", obtidas, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestNicksMencionados(t *testing.T) {
+	publicacao := Publicacao{Titulo: "@ana", Conteudo: "@bia, @ana e @ana."}
+
+	esperado := []string{"ana", "bia"}
+	if obtidos := publicacao.NicksMencionados(); !reflect.DeepEqual(obtidos, esperado) {
+		t.Errorf("NicksMencionados() = %q, esperado %q", obtidos, esperado)
+	}
+}
@@ -5,3 +5,14 @@ type Senha struct {
 	Nova  string `json:"nova"`
 	Atual string `json:"atual"`
 }
+
+// EsqueciSenha representa o formato da requisição de recuperação de senha
+type EsqueciSenha struct {
+	Email string `json:"email"`
+}
+
+// RedefinicaoSenha representa o formato da requisição que troca a senha usando o token recebido por email
+type RedefinicaoSenha struct {
+	Token string `json:"token"`
+	Nova  string `json:"nova"`
+}
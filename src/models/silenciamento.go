@@ -0,0 +1,23 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Silenciamento representa um usuário silenciado no feed de quem o silenciou
+type Silenciamento struct {
+	UsuarioID uint64     `json:"usuarioId,omitempty"`
+	Nick      string     `json:"nick,omitempty"`
+	ExpiraEm  *time.Time `json:"expiraEm,omitempty"`
+	CriadoEm  time.Time  `json:"criadoEm,omitempty"`
+}
+
+// Validar verifica se a data de expiração, quando informada, está no futuro
+func (silenciamento *Silenciamento) Validar() error {
+	if silenciamento.ExpiraEm != nil && !silenciamento.ExpiraEm.After(time.Now()) {
+		return errors.New("a data de expiração precisa estar no futuro")
+	}
+
+	return nil
+}
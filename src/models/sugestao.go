@@ -0,0 +1,31 @@
+package models
+
+import "fmt"
+
+// Sugestao representa uma conta recomendada para o usuário seguir, com o motivo da recomendação
+type Sugestao struct {
+	Usuario
+	SeguidoresEmComum uint64   `json:"seguidoresEmComum"`
+	Seguidores        uint64   `json:"seguidores"`
+	Motivo            string   `json:"motivo"`
+	NicksEmComum      []string `json:"-"`
+}
+
+// GerarMotivo monta a frase que explica a sugestão: quem o usuário segue que também segue a conta,
+// ou a popularidade dela quando não há ninguém em comum
+func (sugestao *Sugestao) GerarMotivo() {
+	if sugestao.SeguidoresEmComum == 0 || len(sugestao.NicksEmComum) == 0 {
+		sugestao.Motivo = "Popular na rede"
+		return
+	}
+
+	quem := sugestao.NicksEmComum[0]
+	if sugestao.SeguidoresEmComum == 2 && len(sugestao.NicksEmComum) > 1 {
+		quem = fmt.Sprintf("%s e %s", quem, sugestao.NicksEmComum[1])
+	} else if sugestao.SeguidoresEmComum > 1 {
+		outros := sugestao.SeguidoresEmComum - 1
+		quem = fmt.Sprintf("%s e mais %d %s", quem, outros, escolher(outros == 1, "pessoa", "pessoas"))
+	}
+
+	sugestao.Motivo = "Seguido por " + quem + " que você segue"
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SemAcento deixa o texto em minúsculas e sem acentos. Os acentos são tirados decompondo as letras (NFD) e
+// descartando as marcas combinantes, o que também trata o texto que já chega decomposto, como o digitado no
+// macOS e no iOS. Faz em Go o mesmo que a função sem_acento do banco.
+func SemAcento(texto string) string {
+	var normalizado strings.Builder
+	for _, letra := range norm.NFD.String(strings.ToLower(texto)) {
+		if unicode.Is(unicode.Mn, letra) {
+			continue
+		}
+		normalizado.WriteRune(letra)
+	}
+
+	return norm.NFC.String(normalizado.String())
+}
+
+// ContemPalavra informa se a palavra (ou expressão) aparece inteira no texto, sem diferenciar maiúsculas nem
+// acentos: "ar" não aparece em "carro", e "eleicao" aparece em "Eleição!". É a mesma regra usada pelo banco
+// nos filtros de palavras do feed.
+func ContemPalavra(texto, palavra string) bool {
+	texto, palavra = SemAcento(texto), SemAcento(palavra)
+	if palavra == "" {
+		return false
+	}
+
+	for inicio := 0; inicio <= len(texto)-len(palavra); {
+		posicao := strings.Index(texto[inicio:], palavra)
+		if posicao < 0 {
+			return false
+		}
+		posicao += inicio
+		fim := posicao + len(palavra)
+
+		antes, _ := utf8.DecodeLastRuneInString(texto[:posicao])
+		depois, _ := utf8.DecodeRuneInString(texto[fim:])
+		if !caractereDePalavra(antes) && !caractereDePalavra(depois) {
+			return true
+		}
+
+		_, tamanho := utf8.DecodeRuneInString(texto[posicao:])
+		inicio = posicao + tamanho
+	}
+
+	return false
+}
+
+// caractereDePalavra segue a definição de palavra das expressões regulares do Postgres: letras, números e _
+func caractereDePalavra(letra rune) bool {
+	return unicode.IsLetter(letra) || unicode.IsDigit(letra) || letra == '_'
+}
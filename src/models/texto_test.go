@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestSemAcento(t *testing.T) {
+	casos := []struct {
+		nome     string
+		texto    string
+		esperado string
+	}{
+		{"composto", "Eleição", "eleicao"},
+		{"decomposto", "Eleic\u0327a\u0303o", "eleicao"},
+		{"sem acento", "ELEICAO", "eleicao"},
+		{"vazio", "", ""},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if obtido := SemAcento(caso.texto); obtido != caso.esperado {
+				t.Errorf("SemAcento(%q) = %q, esperado %q", caso.texto, obtido, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestContemPalavra(t *testing.T) {
+	casos := []struct {
+		nome     string
+		texto    string
+		palavra  string
+		esperado bool
+	}{
+		{"palavra inteira", "vou de carro", "carro", true},
+		{"dentro de outra palavra", "vou de carro", "ar", false},
+		{"começo do texto", "carro novo", "carro", true},
+		{"fim com pontuação", "Comprei um carro.", "carro", true},
+		{"maiúsculas", "CARRO novo", "carro", true},
+		{"acento no texto", "Eleição!", "eleicao", true},
+		{"acento na palavra", "eleicao amanha", "eleição", true},
+		{"texto decomposto", "Eleic\u0327a\u0303o", "eleicao", true},
+		{"expressão", "vamos a praia hoje", "a praia", true},
+		{"segunda ocorrência inteira", "carrossel e carro", "carro", true},
+		{"número colado", "carro2", "carro", false},
+		{"sublinhado colado", "meu_carro", "carro", false},
+		{"caractere especial", "isso é c++ puro", "c++", true},
+		{"palavra vazia", "qualquer coisa", "", false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if obtido := ContemPalavra(caso.texto, caso.palavra); obtido != caso.esperado {
+				t.Errorf("ContemPalavra(%q, %q) = %v, esperado %v", caso.texto, caso.palavra, obtido, caso.esperado)
+			}
+		})
+	}
+}
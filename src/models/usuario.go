@@ -3,8 +3,11 @@ package models
 import (
 	"api/src/seguranca"
 	"errors"
+	"fmt"
+	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/badoux/checkmail"
 )
@@ -17,6 +20,62 @@ type Usuario struct {
 	Email    string    `json:"email,omitempty"`
 	Senha    string    `json:"senha,omitempty"`
 	CriadoEm time.Time `json:"CriadoEm,omitempty"`
+
+	EmailVerificado bool   `json:"emailVerificado,omitempty"`
+	Papel           string `json:"papel,omitempty"`
+	Suspenso        bool   `json:"suspenso,omitempty"`
+	Privado         bool   `json:"privado,omitempty"`
+	VersaoSessao    uint64 `json:"-"`
+
+	Bio         string `json:"bio,omitempty"`
+	Website     string `json:"website,omitempty"`
+	Localizacao string `json:"localizacao,omitempty"`
+	Avatar      *Midia `json:"avatar,omitempty"`
+	Banner      *Midia `json:"banner,omitempty"`
+}
+
+// Limites dos campos de perfil, em caracteres
+const (
+	TamanhoMaximoBio         = 160
+	TamanhoMaximoWebsite     = 100
+	TamanhoMaximoLocalizacao = 30
+)
+
+// Imagens de perfil que podem ser enviadas, usadas na rota e como nome da coluna
+const (
+	ImagemPerfilAvatar = "avatar"
+	ImagemPerfilBanner = "banner"
+)
+
+// MidiasDescartadas é o evento de arquivos que deixaram de ser usados e precisam ser apagados do armazenamento
+type MidiasDescartadas struct {
+	ChavesMidias []string `json:"chavesMidias"`
+}
+
+// Seguimento representa um usuário passando a seguir outro (ou pedindo para seguir um perfil privado).
+// Aprovado indica que o follow veio da aprovação de um pedido pelo próprio dono do perfil.
+type Seguimento struct {
+	UsuarioID  uint64 `json:"usuarioId"`
+	SeguidorID uint64 `json:"seguidorId"`
+	Aprovado   bool   `json:"aprovado,omitempty"`
+}
+
+// Papel representa o formato da requisição que altera o papel de um usuário
+type Papel struct {
+	Papel string `json:"papel"`
+}
+
+// Privacidade representa o formato da requisição que torna um perfil privado ou público
+type Privacidade struct {
+	Privado bool `json:"privado"`
+}
+
+// SolicitacaoSeguir representa um pedido pendente para seguir um perfil privado
+type SolicitacaoSeguir struct {
+	SolicitanteID uint64    `json:"solicitanteId,omitempty"`
+	Nome          string    `json:"nome,omitempty"`
+	Nick          string    `json:"nick,omitempty"`
+	CriadaEm      time.Time `json:"criadaEm,omitempty"`
 }
 
 // Preparar vai chamar os méroos para validar e formatar o usuário recebido
@@ -53,13 +112,72 @@ func (usuario *Usuario) validar(etapa string) error {
 		return errors.New("A senha é obrigatório e não pode estar em branco")
 	}
 
+	if utf8.RuneCountInString(strings.TrimSpace(usuario.Bio)) > TamanhoMaximoBio {
+		return fmt.Errorf("A bio pode ter no máximo %d caracteres", TamanhoMaximoBio)
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(usuario.Localizacao)) > TamanhoMaximoLocalizacao {
+		return fmt.Errorf("A localização pode ter no máximo %d caracteres", TamanhoMaximoLocalizacao)
+	}
+
+	if erro := validarWebsite(strings.TrimSpace(usuario.Website)); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// validarWebsite aceita endereços http e https, com ou sem o esquema (que é completado ao formatar)
+func validarWebsite(website string) error {
+	if website == "" {
+		return nil
+	}
+
+	if utf8.RuneCountInString(website) > TamanhoMaximoWebsite {
+		return fmt.Errorf("O website pode ter no máximo %d caracteres", TamanhoMaximoWebsite)
+	}
+
+	endereco, erro := url.Parse(completarEsquema(website))
+	if erro != nil || (endereco.Scheme != "http" && endereco.Scheme != "https") ||
+		!strings.Contains(endereco.Hostname(), ".") || endereco.User != nil {
+		return errors.New("O website inserido é inválido")
+	}
+
 	return nil
 }
 
+func completarEsquema(website string) string {
+	if strings.Contains(website, "://") {
+		return website
+	}
+	return "https://" + website
+}
+
+// Publico retorna o usuário sem os dados que só o próprio dono pode ver, como o email e a senha, nem o papel
+// e a situação de moderação da conta
+func (usuario Usuario) Publico() Usuario {
+	usuario.Email = ""
+	usuario.Senha = ""
+	usuario.EmailVerificado = false
+	usuario.Papel = ""
+	usuario.Suspenso = false
+	return usuario
+}
+
 func (usuario *Usuario) formatar(etapa string) error{
 	usuario.Nome = strings.TrimSpace(usuario.Nome)
 	usuario.Nick = strings.TrimSpace(usuario.Nick)
 	usuario.Email = strings.TrimSpace(usuario.Email)
+	usuario.Bio = strings.TrimSpace(usuario.Bio)
+	usuario.Localizacao = strings.TrimSpace(usuario.Localizacao)
+
+	// Avatar e banner só mudam pelas rotas de envio de imagem
+	usuario.Avatar = nil
+	usuario.Banner = nil
+
+	if usuario.Website = strings.TrimSpace(usuario.Website); usuario.Website != "" {
+		usuario.Website = completarEsquema(usuario.Website)
+	}
 
 	if etapa == "cadastro" {
 		senhaComHash, erro := seguranca.Hash(usuario.Senha)
@@ -72,3 +190,14 @@ func (usuario *Usuario) formatar(etapa string) error{
 
 	return nil
 }
+
+// Perfil representa o perfil público de um usuário, com os totais e a relação com quem está vendo
+type Perfil struct {
+	Usuario
+	Seguidores          uint64 `json:"seguidores"`
+	Seguindo            uint64 `json:"seguindo"`
+	Publicacoes         uint64 `json:"publicacoes"`
+	VoceSegue           bool   `json:"voceSegue"`
+	SegueVoce           bool   `json:"segueVoce"`
+	SolicitacaoPendente bool   `json:"solicitacaoPendente"`
+}
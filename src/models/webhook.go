@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Situações de uma entrega de webhook
+const (
+	StatusEntregaPendente = "pendente"
+	StatusEntregaEntregue = "entregue"
+	StatusEntregaFalhou   = "falhou"
+)
+
+// Webhook representa uma URL externa que recebe os eventos assinados
+type Webhook struct {
+	ID       uint64    `json:"id,omitempty"`
+	URL      string    `json:"url,omitempty"`
+	Eventos  []string  `json:"eventos,omitempty"`
+	Segredo  string    `json:"segredo,omitempty"`
+	Ativo    bool      `json:"ativo"`
+	CriadoEm time.Time `json:"criadoEm,omitempty"`
+}
+
+// EntregaWebhook representa uma tentativa de entregar um evento a um webhook
+type EntregaWebhook struct {
+	ID               uint64     `json:"id,omitempty"`
+	WebhookID        uint64     `json:"webhookId,omitempty"`
+	Evento           string     `json:"evento,omitempty"`
+	Payload          string     `json:"payload,omitempty"`
+	Status           string     `json:"status,omitempty"`
+	Tentativas       uint64     `json:"tentativas"`
+	ProximaTentativa *time.Time `json:"proximaTentativa,omitempty"`
+	UltimoStatusHTTP uint64     `json:"ultimoStatusHttp,omitempty"`
+	UltimoErro       string     `json:"ultimoErro,omitempty"`
+	CriadaEm         time.Time  `json:"criadaEm,omitempty"`
+	EntregueEm       *time.Time `json:"entregueEm,omitempty"`
+}
+
+// EventoWebhookValido verifica se o evento pode ser assinado por um webhook
+func EventoWebhookValido(evento string) bool {
+	switch evento {
+	case EventoUsuarioCriado, EventoUsuarioSeguido, EventoPublicacaoCriada,
+		EventoPublicacaoEditada, EventoPublicacaoExcluida:
+		return true
+	}
+	return false
+}
+
+// Preparar vai validar e formatar o webhook recebido
+func (webhook *Webhook) Preparar() error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+
+	endereco, erro := url.Parse(webhook.URL)
+	if erro != nil || (endereco.Scheme != "http" && endereco.Scheme != "https") || endereco.Host == "" {
+		return errors.New("a URL do webhook deve ser um endereço http ou https válido")
+	}
+
+	if len(webhook.Eventos) == 0 {
+		return errors.New("informe ao menos um evento para o webhook")
+	}
+
+	vistos := make(map[string]bool)
+	var eventos []string
+	for _, evento := range webhook.Eventos {
+		evento = strings.TrimSpace(evento)
+		if !EventoWebhookValido(evento) {
+			return errors.New("evento de webhook inválido: " + evento)
+		}
+		if !vistos[evento] {
+			vistos[evento] = true
+			eventos = append(eventos, evento)
+		}
+	}
+	webhook.Eventos = eventos
+
+	webhook.Segredo = strings.TrimSpace(webhook.Segredo)
+	if webhook.Segredo != "" && (len(webhook.Segredo) < 16 || len(webhook.Segredo) > 100) {
+		return errors.New("o segredo do webhook deve ter entre 16 e 100 caracteres")
+	}
+
+	return nil
+}
+
+// EnvioWebhook reúne uma entrega pendente com o destino e o segredo usados para enviá-la
+type EnvioWebhook struct {
+	Entrega EntregaWebhook
+	URL     string
+	Segredo string
+}
@@ -0,0 +1,92 @@
+package repository
+
+import "api/src/models"
+
+// Ordens aceitas na busca de publicações
+const (
+	OrdemRelevancia = "relevancia"
+	OrdemRecentes   = "recentes"
+)
+
+// escaparHTML escapa o texto de uma coluna antes do ts_headline, para que só as marcações do destaque sejam HTML
+func escaparHTML(coluna string) string {
+	return `replace(replace(replace(` + coluna + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+}
+
+// opcoesDestaque configura os trechos devolvidos pelo ts_headline
+const opcoesDestaque = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+
+// Pesquisar faz a busca textual nas publicações que o solicitante pode ver. O texto aceita a sintaxe de buscadores
+// ("frase exata", -excluir, or). Na ordem por relevância, a nota da busca (título pesa mais que o conteúdo) é
+// dividida por 1 + idade/30 dias: cai para a metade aos 30 dias, para um terço aos 60 e assim por diante, sem nunca
+// zerar. Na ordem por recentes, só as mais novas vêm primeiro.
+func (repositorio Publicacoes) Pesquisar(texto string, solicitanteID uint64, ordem string, limite, deslocamento uint64) ([]models.PublicacaoEncontrada, error) {
+	ordenacao := "relevancia DESC, p.id DESC"
+	if ordem == OrdemRecentes {
+		ordenacao = "p.id DESC"
+	}
+
+	linhas, erro := repositorio.db.Query(
+		`WITH consulta AS (SELECT websearch_to_tsquery('portuguese', $1) AS q)
+        SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em, u.nick,
+               ts_headline('portuguese', `+escaparHTML("p.titulo")+`, consulta.q,
+                   'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+               ts_headline('portuguese', `+escaparHTML("p.conteudo")+`, consulta.q, '`+opcoesDestaque+`'),
+               ts_rank_cd(p.busca, consulta.q, 1)
+                 / (1 + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - p.criado_em) / 2592000) AS relevancia
+        FROM publicacoes p
+        JOIN usuarios u ON u.id = p.autor_id
+        CROSS JOIN consulta
+        WHERE p.busca @@ consulta.q
+          AND `+visivelPara("$2")+`
+        ORDER BY `+ordenacao+`
+        LIMIT $3 OFFSET $4`,
+		texto, solicitanteID, limite, deslocamento,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var (
+		publicacoes []models.Publicacao
+		encontradas []models.PublicacaoEncontrada
+	)
+	for linhas.Next() {
+		var (
+			publicacao models.Publicacao
+			encontrada models.PublicacaoEncontrada
+		)
+		if erro = linhas.Scan(
+			&publicacao.ID,
+			&publicacao.Titulo,
+			&publicacao.Conteudo,
+			&publicacao.AutorID,
+			&publicacao.Curtidas,
+			&publicacao.CriadaEm,
+			&publicacao.AutorNick,
+			&encontrada.TituloDestacado,
+			&encontrada.Trecho,
+			&encontrada.Relevancia,
+		); erro != nil {
+			return nil, erro
+		}
+
+		publicacoes = append(publicacoes, publicacao)
+		encontradas = append(encontradas, encontrada)
+	}
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+	linhas.Close()
+
+	if erro = carregarDetalhes(repositorio.db, publicacoes, solicitanteID); erro != nil {
+		return nil, erro
+	}
+
+	for i := range encontradas {
+		encontradas[i].Publicacao = publicacoes[i]
+	}
+
+	return encontradas, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// carregarCitadas preenche a publicação citada por cada citação, do ponto de vista do solicitante. O original que
+// foi excluído ou que o solicitante não pode ver vem só com o ID, marcado como indisponível.
+func carregarCitadas(db consultor, publicacoes []models.Publicacao, solicitanteID uint64) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	posicoes := make(map[int64][]int, len(publicacoes))
+	IDs := make([]int64, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		if _, existe := posicoes[int64(publicacao.ID)]; !existe {
+			IDs = append(IDs, int64(publicacao.ID))
+		}
+		posicoes[int64(publicacao.ID)] = append(posicoes[int64(publicacao.ID)], i)
+	}
+
+	// O original é a publicação p, de autor u, para reaproveitar o filtro de visibilidade
+	linhas, erro := db.Query(
+		`SELECT c.id, c.citada_id, p.id IS NOT NULL AND `+visivelPara("$2")+`,
+               p.titulo, p.conteudo, p.autor_id, u.nick, p.criado_em
+        FROM publicacoes c
+        LEFT JOIN publicacoes p ON p.id = c.citada_id
+        LEFT JOIN usuarios u ON u.id = p.autor_id
+        WHERE c.id = ANY($1) AND c.citada_id IS NOT NULL`,
+		pq.Array(IDs), solicitanteID,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var (
+			publicacaoID int64
+			citada       models.PublicacaoCitada
+			titulo       sql.NullString
+			conteudo     sql.NullString
+			autorID      sql.NullInt64
+			autorNick    sql.NullString
+			criadaEm     sql.NullTime
+		)
+		if erro = linhas.Scan(
+			&publicacaoID,
+			&citada.ID,
+			&citada.Disponivel,
+			&titulo,
+			&conteudo,
+			&autorID,
+			&autorNick,
+			&criadaEm,
+		); erro != nil {
+			return erro
+		}
+
+		if citada.Disponivel {
+			citada.Titulo = titulo.String
+			citada.Conteudo = conteudo.String
+			citada.AutorID = uint64(autorID.Int64)
+			citada.AutorNick = autorNick.String
+			citada.CriadaEm = &criadaEm.Time
+		}
+
+		for _, i := range posicoes[publicacaoID] {
+			publicacoes[i].CitadaID = citada.ID
+			publicacoes[i].Citada = &citada
+		}
+	}
+
+	return linhas.Err()
+}
+
+// BuscarCitacoes traz as publicações que citam a publicação informada e que o solicitante pode ver,
+// das mais novas para as mais antigas
+func (repositorio Publicacoes) BuscarCitacoes(publicacaoID, solicitanteID, limite, deslocamento uint64) ([]models.Publicacao, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em, u.nick
+        FROM publicacoes p
+        JOIN usuarios u ON u.id = p.autor_id
+        WHERE p.citada_id = $1
+          AND `+visivelPara("$2")+`
+        ORDER BY p.id DESC
+        LIMIT $3 OFFSET $4`,
+		publicacaoID, solicitanteID, limite, deslocamento,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return escanearPublicacoes(repositorio.db, linhas, solicitanteID)
+}
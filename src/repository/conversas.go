@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// Conversas representa um repositório de conversas e mensagens diretas
+type Conversas struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeConversas cria um repositório de conversas
+func NovoRepositorioDeConversas(db *sql.DB) *Conversas {
+	return &Conversas{db}
+}
+
+// BuscarConversaDireta traz o ID da conversa que tem exatamente os dois usuários, ou 0 se ela não existir
+func (repositorio Conversas) BuscarConversaDireta(usuarioID, outroUsuarioID uint64) (uint64, error) {
+	var conversaID uint64
+	erro := repositorio.db.QueryRow(
+		`SELECT pc.conversa_id
+        FROM participantes_conversa pc
+        WHERE pc.usuario_id IN ($1, $2)
+        GROUP BY pc.conversa_id
+        HAVING COUNT(*) = 2
+           AND (SELECT COUNT(*) FROM participantes_conversa t WHERE t.conversa_id = pc.conversa_id) = 2
+        LIMIT 1`,
+		usuarioID, outroUsuarioID,
+	).Scan(&conversaID)
+	if erro == sql.ErrNoRows {
+		return 0, nil
+	}
+	if erro != nil {
+		return 0, erro
+	}
+
+	return conversaID, nil
+}
+
+// Criar insere uma conversa com os participantes informados
+func (repositorio Conversas) Criar(participantes []uint64) (uint64, error) {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return 0, erro
+	}
+	defer transacao.Rollback()
+
+	var conversaID uint64
+	if erro = transacao.QueryRow(
+		`INSERT INTO conversas DEFAULT VALUES
+        RETURNING id`,
+	).Scan(&conversaID); erro != nil {
+		return 0, erro
+	}
+
+	for _, participanteID := range participantes {
+		if _, erro = transacao.Exec(
+			`INSERT INTO participantes_conversa (conversa_id, usuario_id)
+            VALUES ($1, $2)`,
+			conversaID, participanteID,
+		); erro != nil {
+			return 0, erro
+		}
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, erro
+	}
+
+	return conversaID, nil
+}
+
+// Participa informa se o usuário faz parte da conversa
+func (repositorio Conversas) Participa(conversaID, usuarioID uint64) (bool, error) {
+	var participa bool
+	erro := repositorio.db.QueryRow(
+		`SELECT EXISTS (
+           SELECT 1 FROM participantes_conversa
+           WHERE conversa_id = $1 AND usuario_id = $2
+        )`,
+		conversaID, usuarioID,
+	).Scan(&participa)
+	if erro != nil {
+		return false, erro
+	}
+
+	return participa, nil
+}
+
+// ExisteBloqueio informa se há bloqueio, em qualquer sentido, entre o usuário e outro participante da conversa
+func (repositorio Conversas) ExisteBloqueio(conversaID, usuarioID uint64) (bool, error) {
+	var existe bool
+	erro := repositorio.db.QueryRow(
+		`SELECT EXISTS (
+           SELECT 1
+           FROM participantes_conversa pc
+           INNER JOIN bloqueios b
+             ON (b.usuario_id = pc.usuario_id AND b.bloqueado_id = $2)
+             OR (b.usuario_id = $2 AND b.bloqueado_id = pc.usuario_id)
+           WHERE pc.conversa_id = $1 AND pc.usuario_id <> $2
+        )`,
+		conversaID, usuarioID,
+	).Scan(&existe)
+	if erro != nil {
+		return false, erro
+	}
+
+	return existe, nil
+}
+
+// BuscarPorUsuario traz as conversas do usuário com a última mensagem e a quantidade de não lidas,
+// das mais recentes para as mais antigas
+func (repositorio Conversas) BuscarPorUsuario(usuarioID uint64) ([]models.Conversa, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT c.id, c.criado_em, c.atualizado_em,
+               (SELECT COUNT(*) FROM mensagens m
+                WHERE m.conversa_id = c.id AND m.id > pc.ultima_lida_id AND m.remetente_id <> $1),
+               um.id, um.remetente_id, um.conteudo, um.criado_em, ur.nick
+        FROM participantes_conversa pc
+        INNER JOIN conversas c ON c.id = pc.conversa_id
+        LEFT JOIN LATERAL (
+          SELECT m.id, m.remetente_id, m.conteudo, m.criado_em
+          FROM mensagens m
+          WHERE m.conversa_id = c.id
+          ORDER BY m.id DESC
+          LIMIT 1
+        ) um ON TRUE
+        LEFT JOIN usuarios ur ON ur.id = um.remetente_id
+        WHERE pc.usuario_id = $1
+        ORDER BY c.atualizado_em DESC, c.id DESC`,
+		usuarioID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var (
+		conversas []models.Conversa
+		ids       []int64
+	)
+	for linhas.Next() {
+		var (
+			conversa      models.Conversa
+			mensagemID    sql.NullInt64
+			remetenteID   sql.NullInt64
+			conteudo      sql.NullString
+			criadaEm      sql.NullTime
+			remetenteNick sql.NullString
+		)
+
+		if erro = linhas.Scan(
+			&conversa.ID,
+			&conversa.CriadaEm,
+			&conversa.AtualizadaEm,
+			&conversa.NaoLidas,
+			&mensagemID,
+			&remetenteID,
+			&conteudo,
+			&criadaEm,
+			&remetenteNick,
+		); erro != nil {
+			return nil, erro
+		}
+
+		if mensagemID.Valid {
+			conversa.UltimaMensagem = &models.Mensagem{
+				ID:            uint64(mensagemID.Int64),
+				ConversaID:    conversa.ID,
+				RemetenteID:   uint64(remetenteID.Int64),
+				RemetenteNick: remetenteNick.String,
+				Conteudo:      conteudo.String,
+				CriadaEm:      criadaEm.Time,
+			}
+		}
+
+		conversas = append(conversas, conversa)
+		ids = append(ids, int64(conversa.ID))
+	}
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	if len(conversas) == 0 {
+		return conversas, nil
+	}
+
+	participantes, erro := repositorio.buscarParticipantes(ids)
+	if erro != nil {
+		return nil, erro
+	}
+
+	for i := range conversas {
+		conversas[i].Participantes = participantes[conversas[i].ID]
+	}
+
+	return conversas, nil
+}
+
+// BuscarParticipantes traz os participantes de uma conversa
+func (repositorio Conversas) BuscarParticipantes(conversaID uint64) ([]models.ParticipanteConversa, error) {
+	participantes, erro := repositorio.buscarParticipantes([]int64{int64(conversaID)})
+	if erro != nil {
+		return nil, erro
+	}
+
+	return participantes[conversaID], nil
+}
+
+func (repositorio Conversas) buscarParticipantes(conversasIDs []int64) (map[uint64][]models.ParticipanteConversa, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT pc.conversa_id, u.id, u.nick
+        FROM participantes_conversa pc
+        INNER JOIN usuarios u ON u.id = pc.usuario_id
+        WHERE pc.conversa_id = ANY($1)
+        ORDER BY pc.conversa_id, u.nick`,
+		pq.Array(conversasIDs),
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	participantes := make(map[uint64][]models.ParticipanteConversa)
+	for linhas.Next() {
+		var (
+			conversaID   uint64
+			participante models.ParticipanteConversa
+		)
+
+		if erro = linhas.Scan(&conversaID, &participante.UsuarioID, &participante.Nick); erro != nil {
+			return nil, erro
+		}
+
+		participantes[conversaID] = append(participantes[conversaID], participante)
+	}
+
+	return participantes, linhas.Err()
+}
+
+// CriarMensagem insere uma mensagem na conversa e a marca como lida para quem a enviou
+func (repositorio Conversas) CriarMensagem(mensagem models.Mensagem) (models.Mensagem, error) {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return models.Mensagem{}, erro
+	}
+	defer transacao.Rollback()
+
+	if erro = transacao.QueryRow(
+		`INSERT INTO mensagens (conversa_id, remetente_id, conteudo)
+        VALUES ($1, $2, $3)
+        RETURNING id, criado_em`,
+		mensagem.ConversaID, mensagem.RemetenteID, mensagem.Conteudo,
+	).Scan(&mensagem.ID, &mensagem.CriadaEm); erro != nil {
+		return models.Mensagem{}, erro
+	}
+
+	if _, erro = transacao.Exec(
+		`UPDATE conversas
+        SET atualizado_em = $2
+        WHERE id = $1`,
+		mensagem.ConversaID, mensagem.CriadaEm,
+	); erro != nil {
+		return models.Mensagem{}, erro
+	}
+
+	if _, erro = transacao.Exec(
+		`UPDATE participantes_conversa
+        SET ultima_lida_id = $3
+        WHERE conversa_id = $1 AND usuario_id = $2`,
+		mensagem.ConversaID, mensagem.RemetenteID, mensagem.ID,
+	); erro != nil {
+		return models.Mensagem{}, erro
+	}
+
+	if erro = transacao.QueryRow(
+		`SELECT nick FROM usuarios WHERE id = $1`,
+		mensagem.RemetenteID,
+	).Scan(&mensagem.RemetenteNick); erro != nil {
+		return models.Mensagem{}, erro
+	}
+
+	if erro = registrarEvento(transacao, models.EventoMensagemEnviada, mensagem); erro != nil {
+		return models.Mensagem{}, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return models.Mensagem{}, erro
+	}
+
+	return mensagem, nil
+}
+
+// BuscarMensagens traz as mensagens de uma conversa, das mais novas para as mais antigas,
+// começando antes da mensagem informada (0 começa pela mais recente)
+func (repositorio Conversas) BuscarMensagens(conversaID, antesDe, limite uint64) ([]models.Mensagem, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT m.id, m.conversa_id, m.remetente_id, u.nick, m.conteudo, m.criado_em
+        FROM mensagens m
+        INNER JOIN usuarios u ON u.id = m.remetente_id
+        WHERE m.conversa_id = $1
+          AND ($2 = 0 OR m.id < $2)
+        ORDER BY m.id DESC
+        LIMIT $3`,
+		conversaID, antesDe, limite,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var mensagens []models.Mensagem
+	for linhas.Next() {
+		var mensagem models.Mensagem
+		if erro = linhas.Scan(
+			&mensagem.ID,
+			&mensagem.ConversaID,
+			&mensagem.RemetenteID,
+			&mensagem.RemetenteNick,
+			&mensagem.Conteudo,
+			&mensagem.CriadaEm,
+		); erro != nil {
+			return nil, erro
+		}
+
+		mensagens = append(mensagens, mensagem)
+	}
+
+	return mensagens, linhas.Err()
+}
+
+// MarcarComoLida marca todas as mensagens atuais da conversa como lidas pelo usuário
+func (repositorio Conversas) MarcarComoLida(conversaID, usuarioID uint64) error {
+	statement, erro := repositorio.db.Prepare(
+		`UPDATE participantes_conversa
+        SET ultima_lida_id = COALESCE((SELECT MAX(id) FROM mensagens WHERE conversa_id = $1), 0)
+        WHERE conversa_id = $1 AND usuario_id = $2`,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.Exec(conversaID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// Denuncias representa um repositório de denúncias
+type Denuncias struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeDenuncias cria um repositório de denúncias
+func NovoRepositorioDeDenuncias(db *sql.DB) *Denuncias {
+	return &Denuncias{db}
+}
+
+const colunasDenuncia = `id, denunciante_id, usuario_denunciado_id, publicacao_id, motivo, descricao,
+        status, moderador_id, resolucao, observacao, criado_em, resolvido_em`
+
+// Criar insere uma denúncia no banco de dados
+func (repositorio Denuncias) Criar(denuncia models.Denuncia) (uint64, error) {
+	var publicacaoID sql.NullInt64
+	if denuncia.PublicacaoID != 0 {
+		publicacaoID = sql.NullInt64{Int64: int64(denuncia.PublicacaoID), Valid: true}
+	}
+
+	var id uint64
+	erro := repositorio.db.QueryRow(
+		`INSERT INTO denuncias (denunciante_id, usuario_denunciado_id, publicacao_id, motivo, descricao)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id`,
+		denuncia.DenuncianteID, denuncia.UsuarioDenunciadoID, publicacaoID, denuncia.Motivo, denuncia.Descricao,
+	).Scan(&id)
+	if erro != nil {
+		return 0, erro
+	}
+
+	return id, nil
+}
+
+// Buscar traz as denúncias com a situação informada (ou todas, se ela estiver vazia), das mais antigas para as mais novas
+func (repositorio Denuncias) Buscar(status string) ([]models.Denuncia, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT `+colunasDenuncia+`
+        FROM denuncias
+        WHERE $1 = '' OR status = $1
+        ORDER BY criado_em, id`,
+		status,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var denuncias []models.Denuncia
+	for linhas.Next() {
+		denuncia, erro := escanearDenuncia(linhas)
+		if erro != nil {
+			return nil, erro
+		}
+
+		denuncias = append(denuncias, denuncia)
+	}
+
+	return denuncias, linhas.Err()
+}
+
+// BuscarPorID traz uma única denúncia do banco de dados
+func (repositorio Denuncias) BuscarPorID(denunciaID uint64) (models.Denuncia, error) {
+	linha, erro := repositorio.db.Query(
+		`SELECT `+colunasDenuncia+`
+        FROM denuncias
+        WHERE id = $1`,
+		denunciaID,
+	)
+	if erro != nil {
+		return models.Denuncia{}, erro
+	}
+	defer linha.Close()
+
+	if linha.Next() {
+		return escanearDenuncia(linha)
+	}
+
+	return models.Denuncia{}, linha.Err()
+}
+
+// Assumir coloca uma denúncia aberta em análise pelo moderador, retornando false se ela já tiver sido assumida
+func (repositorio Denuncias) Assumir(denunciaID, moderadorID uint64) (bool, error) {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(
+		`UPDATE denuncias
+        SET status = $3, moderador_id = $2
+        WHERE id = $1 AND status = $4`,
+		denunciaID, moderadorID, models.StatusDenunciaEmAnalise, models.StatusDenunciaAberta,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+	if linhas == 0 {
+		return false, nil
+	}
+
+	if erro = registrarHistoricoDenuncia(transacao, denunciaID, moderadorID, "assumida", ""); erro != nil {
+		return false, erro
+	}
+
+	return true, transacao.Commit()
+}
+
+// Resolver encerra a denúncia com a resolução escolhida, retornando false se ela já estiver resolvida. A exclusão da
+// publicação ou a suspensão do autor acontecem na mesma transação que muda o status e grava o histórico.
+func (repositorio Denuncias) Resolver(denuncia models.Denuncia, moderadorID uint64, resolucao models.ResolucaoDenuncia) (bool, error) {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(
+		`UPDATE denuncias
+        SET status = $3, moderador_id = $2, resolucao = $4, observacao = $5, resolvido_em = CURRENT_TIMESTAMP
+        WHERE id = $1 AND status <> $3`,
+		denuncia.ID, moderadorID, models.StatusDenunciaResolvida, resolucao.Resolucao, resolucao.Observacao,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+	if linhas == 0 {
+		return false, nil
+	}
+
+	// A ação só é aplicada por quem conseguiu mudar o status, e é desfeita junto se algo falhar depois
+	switch resolucao.Resolucao {
+	case models.ResolucaoPublicacaoRemovida:
+		erro = deletarPublicacao(transacao, denuncia.PublicacaoID)
+	case models.ResolucaoAutorSuspenso:
+		erro = suspenderUsuario(transacao, denuncia.UsuarioDenunciadoID)
+	}
+	if erro != nil {
+		return false, erro
+	}
+
+	if erro = registrarHistoricoDenuncia(transacao, denuncia.ID, moderadorID, resolucao.Resolucao, resolucao.Observacao); erro != nil {
+		return false, erro
+	}
+
+	return true, transacao.Commit()
+}
+
+// BuscarHistorico traz todas as ações de moderação registradas para uma denúncia
+func (repositorio Denuncias) BuscarHistorico(denunciaID uint64) ([]models.HistoricoDenuncia, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT id, denuncia_id, COALESCE(moderador_id, 0), acao, observacao, criado_em
+        FROM historico_denuncias
+        WHERE denuncia_id = $1
+        ORDER BY criado_em, id`,
+		denunciaID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var historico []models.HistoricoDenuncia
+	for linhas.Next() {
+		var registro models.HistoricoDenuncia
+		if erro = linhas.Scan(
+			&registro.ID,
+			&registro.DenunciaID,
+			&registro.ModeradorID,
+			&registro.Acao,
+			&registro.Observacao,
+			&registro.CriadoEm,
+		); erro != nil {
+			return nil, erro
+		}
+
+		historico = append(historico, registro)
+	}
+
+	return historico, linhas.Err()
+}
+
+func registrarHistoricoDenuncia(transacao *sql.Tx, denunciaID, moderadorID uint64, acao, observacao string) error {
+	_, erro := transacao.Exec(
+		`INSERT INTO historico_denuncias (denuncia_id, moderador_id, acao, observacao)
+        VALUES ($1, $2, $3, $4)`,
+		denunciaID, moderadorID, acao, observacao,
+	)
+	return erro
+}
+
+func escanearDenuncia(linhas *sql.Rows) (models.Denuncia, error) {
+	var (
+		denuncia     models.Denuncia
+		publicacaoID sql.NullInt64
+		moderadorID  sql.NullInt64
+		resolucao    sql.NullString
+		resolvidaEm  sql.NullTime
+	)
+
+	if erro := linhas.Scan(
+		&denuncia.ID,
+		&denuncia.DenuncianteID,
+		&denuncia.UsuarioDenunciadoID,
+		&publicacaoID,
+		&denuncia.Motivo,
+		&denuncia.Descricao,
+		&denuncia.Status,
+		&moderadorID,
+		&resolucao,
+		&denuncia.Observacao,
+		&denuncia.CriadaEm,
+		&resolvidaEm,
+	); erro != nil {
+		return models.Denuncia{}, erro
+	}
+
+	denuncia.PublicacaoID = uint64(publicacaoID.Int64)
+	denuncia.ModeradorID = uint64(moderadorID.Int64)
+	denuncia.Resolucao = resolucao.String
+	if resolvidaEm.Valid {
+		denuncia.ResolvidaEm = &resolvidaEm.Time
+	}
+
+	return denuncia, nil
+}
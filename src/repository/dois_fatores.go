@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"api/src/models"
+	"api/src/seguranca"
+	"database/sql"
+)
+
+// DoisFatores representa um repositório de configurações de autenticação em dois fatores
+type DoisFatores struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeDoisFatores cria um repositório de dois fatores
+func NovoRepositorioDeDoisFatores(db *sql.DB) *DoisFatores {
+	return &DoisFatores{db}
+}
+
+// Cadastrar salva um novo segredo ainda não confirmado para o usuário. O segredo é guardado cifrado.
+func (repositorio DoisFatores) Cadastrar(usuarioID uint64, segredo string) error {
+	segredoCifrado, erro := seguranca.CifrarSegredoTOTP(usuarioID, segredo)
+	if erro != nil {
+		return erro
+	}
+
+	statement, erro := repositorio.db.Prepare(
+		`INSERT INTO dois_fatores (usuario_id, segredo)
+        VALUES ($1, $2)
+        ON CONFLICT (usuario_id) DO UPDATE
+        SET segredo = EXCLUDED.segredo, ativo = FALSE, ultimo_passo = 0, criado_em = CURRENT_TIMESTAMP`,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, segredoCifrado); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Buscar traz a configuração de dois fatores do usuário, com o segredo já decifrado, retornando um valor vazio se
+// ela não existir
+func (repositorio DoisFatores) Buscar(usuarioID uint64) (models.DoisFatores, error) {
+	var doisFatores models.DoisFatores
+	erro := repositorio.db.QueryRow(
+		`SELECT usuario_id, segredo, ativo, ultimo_passo,
+               COALESCE(bloqueado_ate > CURRENT_TIMESTAMP, FALSE), versao_token
+        FROM dois_fatores
+        WHERE usuario_id = $1`,
+		usuarioID,
+	).Scan(
+		&doisFatores.UsuarioID,
+		&doisFatores.Segredo,
+		&doisFatores.Ativo,
+		&doisFatores.UltimoPasso,
+		&doisFatores.Bloqueado,
+		&doisFatores.VersaoToken,
+	)
+	if erro == sql.ErrNoRows {
+		return models.DoisFatores{}, nil
+	}
+	if erro != nil {
+		return models.DoisFatores{}, erro
+	}
+
+	if doisFatores.Segredo, erro = seguranca.DecifrarSegredoTOTP(usuarioID, doisFatores.Segredo); erro != nil {
+		return models.DoisFatores{}, erro
+	}
+
+	return doisFatores, nil
+}
+
+// Ativar liga os dois fatores e substitui os códigos de recuperação do usuário
+func (repositorio DoisFatores) Ativar(usuarioID uint64, passo int64, codigosComHash []string) error {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(
+		`UPDATE dois_fatores
+        SET ativo = TRUE, ultimo_passo = $2
+        WHERE usuario_id = $1`,
+		usuarioID, passo,
+	); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(
+		`DELETE FROM codigos_recuperacao
+        WHERE usuario_id = $1`,
+		usuarioID,
+	); erro != nil {
+		return erro
+	}
+
+	for _, codigoComHash := range codigosComHash {
+		if _, erro = transacao.Exec(
+			`INSERT INTO codigos_recuperacao (usuario_id, codigo_hash)
+            VALUES ($1, $2)`,
+			usuarioID, codigoComHash,
+		); erro != nil {
+			return erro
+		}
+	}
+
+	return transacao.Commit()
+}
+
+// RegistrarPasso guarda o passo do último código aceito, retornando false se ele já tiver sido usado
+func (repositorio DoisFatores) RegistrarPasso(usuarioID uint64, passo int64) (bool, error) {
+	resultado, erro := repositorio.db.Exec(
+		`UPDATE dois_fatores
+        SET ultimo_passo = $2
+        WHERE usuario_id = $1 AND ultimo_passo < $2`,
+		usuarioID, passo,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+
+	return linhas == 1, nil
+}
+
+// UsarCodigoDeRecuperacao consome um código de recuperação, retornando false se ele não existir ou já tiver sido usado
+func (repositorio DoisFatores) UsarCodigoDeRecuperacao(usuarioID uint64, codigoComHash string) (bool, error) {
+	resultado, erro := repositorio.db.Exec(
+		`UPDATE codigos_recuperacao
+        SET usado_em = CURRENT_TIMESTAMP
+        WHERE usuario_id = $1 AND codigo_hash = $2 AND usado_em IS NULL`,
+		usuarioID, codigoComHash,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+
+	return linhas > 0, nil
+}
+
+// RegistrarFalha conta um código errado no login. Ao chegar ao máximo de falhas seguidas, o login com dois fatores
+// fica bloqueado por 15 minutos e os tokens de MFA pendente já emitidos deixam de valer; retorna true nesse caso.
+func (repositorio DoisFatores) RegistrarFalha(usuarioID uint64, maximo int) (bool, error) {
+	var bloqueado bool
+	erro := repositorio.db.QueryRow(
+		`UPDATE dois_fatores
+        SET falhas = CASE WHEN falhas + 1 >= $2 THEN 0 ELSE falhas + 1 END,
+            bloqueado_ate = CASE WHEN falhas + 1 >= $2 THEN CURRENT_TIMESTAMP + INTERVAL '15 minutes' ELSE bloqueado_ate END,
+            versao_token = CASE WHEN falhas + 1 >= $2 THEN versao_token + 1 ELSE versao_token END
+        WHERE usuario_id = $1
+        RETURNING falhas = 0`,
+		usuarioID, maximo,
+	).Scan(&bloqueado)
+	if erro == sql.ErrNoRows {
+		return false, nil
+	}
+	if erro != nil {
+		return false, erro
+	}
+
+	return bloqueado, nil
+}
+
+// ZerarFalhas recomeça a contagem de códigos errados depois de um login bem-sucedido
+func (repositorio DoisFatores) ZerarFalhas(usuarioID uint64) error {
+	_, erro := repositorio.db.Exec(
+		`UPDATE dois_fatores
+        SET falhas = 0
+        WHERE usuario_id = $1`,
+		usuarioID,
+	)
+	return erro
+}
+
+// Desativar remove a configuração de dois fatores e os códigos de recuperação do usuário
+func (repositorio DoisFatores) Desativar(usuarioID uint64) error {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(`DELETE FROM codigos_recuperacao WHERE usuario_id = $1`, usuarioID); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(`DELETE FROM dois_fatores WHERE usuario_id = $1`, usuarioID); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
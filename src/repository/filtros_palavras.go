@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+// FiltrosDePalavras representa um repositório de filtros de palavras do feed
+type FiltrosDePalavras struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeFiltrosDePalavras cria um repositório de filtros de palavras
+func NovoRepositorioDeFiltrosDePalavras(db *sql.DB) *FiltrosDePalavras {
+	return &FiltrosDePalavras{db}
+}
+
+// Criar insere (ou renova a expiração de) um filtro de palavra do usuário
+func (repositorio FiltrosDePalavras) Criar(usuarioID uint64, filtro models.FiltroPalavra) (uint64, error) {
+	var id uint64
+	erro := repositorio.db.QueryRow(
+		`INSERT INTO filtros_palavras (usuario_id, palavra, expira_em)
+        VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3::float8))
+        ON CONFLICT (usuario_id, palavra) DO UPDATE
+        SET expira_em = EXCLUDED.expira_em
+        RETURNING id`,
+		usuarioID, filtro.Palavra, segundosAte(filtro.ExpiraEm),
+	).Scan(&id)
+	if erro != nil {
+		return 0, erro
+	}
+
+	return id, nil
+}
+
+// Buscar traz os filtros ainda válidos do usuário
+func (repositorio FiltrosDePalavras) Buscar(usuarioID uint64) ([]models.FiltroPalavra, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT id, palavra, expira_em, criado_em
+        FROM filtros_palavras
+        WHERE usuario_id = $1
+          AND (expira_em IS NULL OR expira_em > CURRENT_TIMESTAMP)
+        ORDER BY palavra`,
+		usuarioID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var filtros []models.FiltroPalavra
+	for linhas.Next() {
+		var (
+			filtro   models.FiltroPalavra
+			expiraEm sql.NullTime
+		)
+
+		if erro = linhas.Scan(&filtro.ID, &filtro.Palavra, &expiraEm, &filtro.CriadoEm); erro != nil {
+			return nil, erro
+		}
+
+		if expiraEm.Valid {
+			filtro.ExpiraEm = &expiraEm.Time
+		}
+
+		filtros = append(filtros, filtro)
+	}
+
+	return filtros, linhas.Err()
+}
+
+// Deletar exclui um filtro do usuário
+func (repositorio FiltrosDePalavras) Deletar(usuarioID, filtroID uint64) error {
+	statement, erro := repositorio.db.Prepare(
+		`DELETE FROM filtros_palavras
+        WHERE id = $1 AND usuario_id = $2`,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.Exec(filtroID, usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
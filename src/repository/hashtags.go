@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Hashtags representa um repositório de hashtags
+type Hashtags struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeHashtags cria um repositório de hashtags
+func NovoRepositorioDeHashtags(db *sql.DB) *Hashtags {
+	return &Hashtags{db}
+}
+
+// salvarHashtags liga a publicação às hashtags informadas (já normalizadas), criando as que ainda não existem
+// e desfazendo as ligações com as que saíram do texto numa edição
+func salvarHashtags(transacao *sql.Tx, publicacaoID uint64, hashtags []string) error {
+	if _, erro := transacao.Exec(
+		`DELETE FROM publicacoes_hashtags
+        WHERE publicacao_id = $1
+          AND hashtag_id NOT IN (SELECT id FROM hashtags WHERE nome = ANY($2))`,
+		publicacaoID, pq.Array(hashtags),
+	); erro != nil {
+		return erro
+	}
+
+	if len(hashtags) == 0 {
+		return nil
+	}
+
+	if _, erro := transacao.Exec(
+		`INSERT INTO hashtags (nome)
+        SELECT unnest($1::text[])
+        ON CONFLICT (nome) DO NOTHING`,
+		pq.Array(hashtags),
+	); erro != nil {
+		return erro
+	}
+
+	_, erro := transacao.Exec(
+		`INSERT INTO publicacoes_hashtags (hashtag_id, publicacao_id)
+        SELECT id, $1 FROM hashtags WHERE nome = ANY($2)
+        ON CONFLICT DO NOTHING`,
+		publicacaoID, pq.Array(hashtags),
+	)
+	return erro
+}
+
+// BuscarPublicacoes traz as publicações com a hashtag (já normalizada) que o solicitante pode ver,
+// das mais novas para as mais antigas
+func (repositorio Hashtags) BuscarPublicacoes(hashtag string, solicitanteID, limite, deslocamento uint64) ([]models.Publicacao, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em, u.nick
+        FROM hashtags h
+        JOIN publicacoes_hashtags ph ON ph.hashtag_id = h.id
+        JOIN publicacoes p ON p.id = ph.publicacao_id
+        JOIN usuarios u ON u.id = p.autor_id
+        WHERE h.nome = $1
+          AND `+visivelPara("$2")+`
+        ORDER BY p.id DESC
+        LIMIT $3 OFFSET $4`,
+		hashtag, solicitanteID, limite, deslocamento,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return escanearPublicacoes(repositorio.db, linhas, solicitanteID)
+}
+
+// BuscarEmAlta traz as hashtags mais usadas na janela de tempo que termina agora. Contam só publicações de
+// perfis públicos e não suspensos, e o número de autores diferentes pesa antes do número de publicações,
+// para que uma única conta repetindo a mesma hashtag não a coloque em alta.
+func (repositorio Hashtags) BuscarEmAlta(janela time.Duration, limite uint64) ([]models.Hashtag, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT h.nome, COUNT(*) AS publicacoes, COUNT(DISTINCT p.autor_id) AS autores
+        FROM publicacoes p
+        JOIN usuarios u ON u.id = p.autor_id
+        JOIN publicacoes_hashtags ph ON ph.publicacao_id = p.id
+        JOIN hashtags h ON h.id = ph.hashtag_id
+        WHERE p.criado_em > CURRENT_TIMESTAMP - make_interval(secs => $1::float8)
+          AND NOT u.privado
+          AND NOT u.suspenso
+        GROUP BY h.nome
+        ORDER BY autores DESC, publicacoes DESC, MAX(p.criado_em) DESC
+        LIMIT $2`,
+		janela.Seconds(), limite,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var hashtags []models.Hashtag
+	for linhas.Next() {
+		var hashtag models.Hashtag
+		if erro = linhas.Scan(&hashtag.Nome, &hashtag.Publicacoes, &hashtag.Autores); erro != nil {
+			return nil, erro
+		}
+		hashtags = append(hashtags, hashtag)
+	}
+
+	return hashtags, linhas.Err()
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// salvarMencoes troca as menções gravadas da publicação pelas que estão no texto atual, ligando cada @nick
+// ao usuário com esse nick. Nicks que não existem são ignorados. Retorna as menções resolvidas.
+func salvarMencoes(transacao *sql.Tx, publicacao models.Publicacao) ([]models.Mencao, error) {
+	if _, erro := transacao.Exec(`DELETE FROM mencoes WHERE publicacao_id = $1`, publicacao.ID); erro != nil {
+		return nil, erro
+	}
+
+	encontradas := publicacao.ExtrairMencoes()
+	if len(encontradas) == 0 {
+		return nil, nil
+	}
+
+	linhas, erro := transacao.Query(
+		`SELECT id, nick FROM usuarios WHERE nick = ANY($1)`,
+		pq.Array(publicacao.NicksMencionados()),
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	IDs := make(map[string]uint64)
+	for linhas.Next() {
+		var (
+			ID   uint64
+			nick string
+		)
+		if erro = linhas.Scan(&ID, &nick); erro != nil {
+			return nil, erro
+		}
+		IDs[nick] = ID
+	}
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+	linhas.Close()
+
+	var mencoes []models.Mencao
+	for _, mencao := range encontradas {
+		if mencao.UsuarioID = IDs[mencao.Nick]; mencao.UsuarioID == 0 {
+			continue
+		}
+
+		if _, erro = transacao.Exec(
+			`INSERT INTO mencoes (publicacao_id, usuario_id, campo, inicio, fim)
+            VALUES ($1, $2, $3, $4, $5)`,
+			publicacao.ID, mencao.UsuarioID, mencao.Campo, mencao.Inicio, mencao.Fim,
+		); erro != nil {
+			return nil, erro
+		}
+		mencoes = append(mencoes, mencao)
+	}
+
+	return mencoes, nil
+}
+
+// carregarMencoes preenche as menções de cada publicação com o nick atual de cada usuário mencionado
+func carregarMencoes(db consultor, publicacoes []models.Publicacao) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	posicoes := make(map[int64]int, len(publicacoes))
+	IDs := make([]int64, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		posicoes[int64(publicacao.ID)] = i
+		IDs = append(IDs, int64(publicacao.ID))
+	}
+
+	linhas, erro := db.Query(
+		`SELECT m.publicacao_id, m.usuario_id, u.nick, m.campo, m.inicio, m.fim
+        FROM mencoes m
+        JOIN usuarios u ON u.id = m.usuario_id
+        WHERE m.publicacao_id = ANY($1)
+        ORDER BY m.publicacao_id, m.campo DESC, m.inicio`,
+		pq.Array(IDs),
+	)
+	if erro != nil {
+		return erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var (
+			publicacaoID int64
+			mencao       models.Mencao
+		)
+		if erro = linhas.Scan(
+			&publicacaoID,
+			&mencao.UsuarioID,
+			&mencao.Nick,
+			&mencao.Campo,
+			&mencao.Inicio,
+			&mencao.Fim,
+		); erro != nil {
+			return erro
+		}
+
+		i := posicoes[publicacaoID]
+		publicacoes[i].Mencoes = append(publicacoes[i].Mencoes, mencao)
+	}
+
+	return linhas.Err()
+}
+
+// BuscarMencionando traz as publicações que mencionam o usuário e que ele pode ver, das mais novas para as mais antigas
+func (repositorio Publicacoes) BuscarMencionando(usuarioID, limite, deslocamento uint64) ([]models.Publicacao, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em, u.nick
+        FROM publicacoes p
+        JOIN usuarios u ON u.id = p.autor_id
+        WHERE p.id IN (SELECT publicacao_id FROM mencoes WHERE usuario_id = $1)
+          AND `+visivelPara("$1")+`
+        ORDER BY p.id DESC
+        LIMIT $2 OFFSET $3`,
+		usuarioID, limite, deslocamento,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return escanearPublicacoes(repositorio.db, linhas, usuarioID)
+}
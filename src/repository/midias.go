@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// consultor é satisfeito tanto pela conexão quanto por uma transação
+type consultor interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// inserirMidias grava as mídias de uma publicação na ordem em que foram enviadas, preenchendo os seus IDs
+func inserirMidias(transacao *sql.Tx, publicacaoID uint64, midias []models.Midia) ([]models.Midia, error) {
+	for i := range midias {
+		publicacao := sql.NullInt64{Int64: int64(publicacaoID), Valid: true}
+		if erro := inserirMidia(transacao, publicacao, i, &midias[i]); erro != nil {
+			return nil, erro
+		}
+	}
+
+	return midias, nil
+}
+
+// inserirMidia grava uma mídia e as suas variantes. Mídias sem publicação são as imagens de perfil.
+func inserirMidia(transacao *sql.Tx, publicacaoID sql.NullInt64, ordem int, midia *models.Midia) error {
+	if erro := transacao.QueryRow(
+		`INSERT INTO midias (publicacao_id, chave, url, tipo, tamanho, largura, altura, blurhash, ordem)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id, criado_em`,
+		publicacaoID, midia.Chave, midia.URL, midia.Tipo, midia.Tamanho,
+		midia.Largura, midia.Altura, midia.Blurhash, ordem,
+	).Scan(&midia.ID, &midia.CriadaEm); erro != nil {
+		return erro
+	}
+
+	for _, variante := range midia.Variantes {
+		if _, erro := transacao.Exec(
+			`INSERT INTO variantes_midia (midia_id, nome, chave, url, largura, altura)
+            VALUES ($1, $2, $3, $4, $5, $6)`,
+			midia.ID, variante.Nome, variante.Chave, variante.URL, variante.Largura, variante.Altura,
+		); erro != nil {
+			return erro
+		}
+	}
+
+	return nil
+}
+
+// buscarMidiasPorID traz as mídias com os IDs informados, já com as variantes
+func buscarMidiasPorID(db consultor, IDs []int64) (map[int64]*models.Midia, error) {
+	midias := make(map[int64]*models.Midia, len(IDs))
+	if len(IDs) == 0 {
+		return midias, nil
+	}
+
+	linhas, erro := db.Query(
+		`SELECT id, chave, url, tipo, tamanho, largura, altura, blurhash, criado_em
+        FROM midias
+        WHERE id = ANY($1)`,
+		pq.Array(IDs),
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var midia models.Midia
+		if erro = linhas.Scan(
+			&midia.ID,
+			&midia.Chave,
+			&midia.URL,
+			&midia.Tipo,
+			&midia.Tamanho,
+			&midia.Largura,
+			&midia.Altura,
+			&midia.Blurhash,
+			&midia.CriadaEm,
+		); erro != nil {
+			return nil, erro
+		}
+		midias[int64(midia.ID)] = &midia
+	}
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+	linhas.Close()
+
+	if erro = carregarVariantes(db, midias); erro != nil {
+		return nil, erro
+	}
+
+	return midias, nil
+}
+
+// descartarMidias exclui as mídias e as suas variantes e registra, na mesma transação, o evento
+// que apaga os arquivos do armazenamento
+func descartarMidias(transacao *sql.Tx, IDs []int64) error {
+	if len(IDs) == 0 {
+		return nil
+	}
+
+	var descartadas models.MidiasDescartadas
+
+	linhas, erro := transacao.Query(
+		`SELECT chave FROM variantes_midia WHERE midia_id = ANY($1)
+        UNION ALL
+        SELECT chave FROM midias WHERE id = ANY($1)`,
+		pq.Array(IDs),
+	)
+	if erro != nil {
+		return erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var chave string
+		if erro = linhas.Scan(&chave); erro != nil {
+			return erro
+		}
+		descartadas.ChavesMidias = append(descartadas.ChavesMidias, chave)
+	}
+	if erro = linhas.Err(); erro != nil {
+		return erro
+	}
+	linhas.Close()
+
+	if _, erro = transacao.Exec(`DELETE FROM midias WHERE id = ANY($1)`, pq.Array(IDs)); erro != nil {
+		return erro
+	}
+
+	if len(descartadas.ChavesMidias) == 0 {
+		return nil
+	}
+	return registrarEvento(transacao, models.EventoMidiasDescartadas, descartadas)
+}
+
+// carregarMidias preenche as mídias de cada publicação com uma única consulta
+func carregarMidias(db consultor, publicacoes []models.Publicacao) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	posicoes := make(map[int64]int, len(publicacoes))
+	IDs := make([]int64, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		posicoes[int64(publicacao.ID)] = i
+		IDs = append(IDs, int64(publicacao.ID))
+	}
+
+	linhas, erro := db.Query(
+		`SELECT publicacao_id, id, chave, url, tipo, tamanho, largura, altura, blurhash, criado_em
+        FROM midias
+        WHERE publicacao_id = ANY($1)
+        ORDER BY publicacao_id, ordem`,
+		pq.Array(IDs),
+	)
+	if erro != nil {
+		return erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var (
+			publicacaoID int64
+			midia        models.Midia
+		)
+		if erro = linhas.Scan(
+			&publicacaoID,
+			&midia.ID,
+			&midia.Chave,
+			&midia.URL,
+			&midia.Tipo,
+			&midia.Tamanho,
+			&midia.Largura,
+			&midia.Altura,
+			&midia.Blurhash,
+			&midia.CriadaEm,
+		); erro != nil {
+			return erro
+		}
+
+		i := posicoes[publicacaoID]
+		publicacoes[i].Midias = append(publicacoes[i].Midias, midia)
+	}
+	if erro = linhas.Err(); erro != nil {
+		return erro
+	}
+	linhas.Close()
+
+	midias := make(map[int64]*models.Midia)
+	for i := range publicacoes {
+		for j := range publicacoes[i].Midias {
+			midias[int64(publicacoes[i].Midias[j].ID)] = &publicacoes[i].Midias[j]
+		}
+	}
+
+	return carregarVariantes(db, midias)
+}
+
+// carregarVariantes preenche as versões reduzidas das mídias, da menor para a maior
+func carregarVariantes(db consultor, midias map[int64]*models.Midia) error {
+	if len(midias) == 0 {
+		return nil
+	}
+
+	IDs := make([]int64, 0, len(midias))
+	for ID := range midias {
+		IDs = append(IDs, ID)
+	}
+
+	linhas, erro := db.Query(
+		`SELECT midia_id, nome, chave, url, largura, altura
+        FROM variantes_midia
+        WHERE midia_id = ANY($1)
+        ORDER BY midia_id, largura`,
+		pq.Array(IDs),
+	)
+	if erro != nil {
+		return erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var (
+			midiaID  int64
+			variante models.VarianteMidia
+		)
+		if erro = linhas.Scan(
+			&midiaID,
+			&variante.Nome,
+			&variante.Chave,
+			&variante.URL,
+			&variante.Largura,
+			&variante.Altura,
+		); erro != nil {
+			return erro
+		}
+
+		midia := midias[midiaID]
+		midia.Variantes = append(midia.Variantes, variante)
+	}
+
+	return linhas.Err()
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// Notificacoes representa um repositório de notificações
+type Notificacoes struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeNotificacoes cria um repositório de notificações
+func NovoRepositorioDeNotificacoes(db *sql.DB) *Notificacoes {
+	return &Notificacoes{db}
+}
+
+// ExisteParaPublicacao informa se o usuário já recebeu uma notificação do tipo sobre a publicação
+func (repositorio Notificacoes) ExisteParaPublicacao(usuarioID uint64, tipo string, publicacaoID uint64) (bool, error) {
+	var existe bool
+	erro := repositorio.db.QueryRow(
+		`SELECT EXISTS (
+            SELECT 1 FROM notificacoes
+            WHERE usuario_id = $1 AND tipo = $2 AND publicacao_id = $3
+        )`,
+		usuarioID, tipo, publicacaoID,
+	).Scan(&existe)
+	if erro != nil {
+		return false, erro
+	}
+
+	return existe, nil
+}
+
+// Criar registra uma notificação para o usuário, ignorando ações dele mesmo e de quem tem bloqueio com ele.
+// Retorna o ID da notificação criada, ou zero quando ela foi ignorada.
+func (repositorio Notificacoes) Criar(usuarioID uint64, tipo string, atorID, publicacaoID uint64) (uint64, error) {
+	var publicacao sql.NullInt64
+	if publicacaoID != 0 {
+		publicacao = sql.NullInt64{Int64: int64(publicacaoID), Valid: true}
+	}
+
+	var ID uint64
+	erro := repositorio.db.QueryRow(
+		`INSERT INTO notificacoes (usuario_id, tipo, ator_id, publicacao_id)
+        SELECT $1, $2, $3, $4
+        WHERE $1 <> $3
+          AND NOT EXISTS (
+            SELECT 1 FROM bloqueios b
+            WHERE (b.usuario_id = $1 AND b.bloqueado_id = $3)
+               OR (b.usuario_id = $3 AND b.bloqueado_id = $1)
+          )
+        RETURNING id`,
+		usuarioID, tipo, atorID, publicacao,
+	).Scan(&ID)
+	if erro == sql.ErrNoRows {
+		return 0, nil
+	}
+	if erro != nil {
+		return 0, erro
+	}
+
+	return ID, nil
+}
+
+// Buscar traz as notificações do usuário, das mais recentes para as mais antigas, agrupando curtidas
+// da mesma publicação e novos seguidores que ainda estão no mesmo estado de leitura
+func (repositorio Notificacoes) Buscar(usuarioID, limite, deslocamento uint64) ([]models.Notificacao, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT MAX(n.id), n.tipo, COALESCE(n.publicacao_id, 0), n.lida, MAX(n.criado_em),
+               COUNT(DISTINCT n.ator_id),
+               (array_agg(n.ator_id ORDER BY n.id DESC))[1:10]
+        FROM notificacoes n
+        WHERE n.usuario_id = $1
+        GROUP BY n.tipo, n.publicacao_id, n.lida,
+                 CASE WHEN n.tipo IN ($4, $5) THEN 0 ELSE n.id END
+        ORDER BY MAX(n.id) DESC
+        LIMIT $2 OFFSET $3`,
+		usuarioID, limite, deslocamento, models.NotificacaoCurtida, models.NotificacaoSeguidor,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var (
+		notificacoes []models.Notificacao
+		atoresPorID  = make(map[int64]bool)
+		atores       [][]int64
+	)
+	for linhas.Next() {
+		var (
+			notificacao models.Notificacao
+			ids         []int64
+		)
+
+		if erro = linhas.Scan(
+			&notificacao.ID,
+			&notificacao.Tipo,
+			&notificacao.PublicacaoID,
+			&notificacao.Lida,
+			&notificacao.CriadaEm,
+			&notificacao.TotalAtores,
+			pq.Array(&ids),
+		); erro != nil {
+			return nil, erro
+		}
+
+		ids = primeirosDistintos(ids, 3)
+		for _, id := range ids {
+			atoresPorID[id] = true
+		}
+
+		notificacoes = append(notificacoes, notificacao)
+		atores = append(atores, ids)
+	}
+	if erro = linhas.Err(); erro != nil {
+		return nil, erro
+	}
+
+	nicks, erro := repositorio.buscarNicks(atoresPorID)
+	if erro != nil {
+		return nil, erro
+	}
+
+	for i := range notificacoes {
+		for _, id := range atores[i] {
+			notificacoes[i].Atores = append(notificacoes[i].Atores, models.AtorNotificacao{
+				UsuarioID: uint64(id),
+				Nick:      nicks[id],
+			})
+		}
+		notificacoes[i].GerarTexto()
+	}
+
+	return notificacoes, nil
+}
+
+// ContarNaoLidas traz quantas notificações do usuário ainda não foram lidas
+func (repositorio Notificacoes) ContarNaoLidas(usuarioID uint64) (uint64, error) {
+	var total uint64
+	erro := repositorio.db.QueryRow(
+		`SELECT COUNT(*)
+        FROM notificacoes
+        WHERE usuario_id = $1 AND lida = FALSE`,
+		usuarioID,
+	).Scan(&total)
+	if erro != nil {
+		return 0, erro
+	}
+
+	return total, nil
+}
+
+// MarcarComoLida marca a notificação (e, se ela for agregada, as outras do mesmo grupo) como lida,
+// retornando false se ela não pertencer ao usuário
+func (repositorio Notificacoes) MarcarComoLida(usuarioID, notificacaoID uint64) (bool, error) {
+	var existe bool
+	if erro := repositorio.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM notificacoes WHERE id = $1 AND usuario_id = $2)`,
+		notificacaoID, usuarioID,
+	).Scan(&existe); erro != nil {
+		return false, erro
+	}
+	if !existe {
+		return false, nil
+	}
+
+	_, erro := repositorio.db.Exec(
+		`UPDATE notificacoes n
+        SET lida = TRUE
+        FROM notificacoes alvo
+        WHERE alvo.id = $1
+          AND n.usuario_id = $2
+          AND n.lida = FALSE
+          AND (n.id = alvo.id OR (
+            alvo.tipo IN ($3, $4)
+            AND n.tipo = alvo.tipo
+            AND n.publicacao_id IS NOT DISTINCT FROM alvo.publicacao_id
+            AND n.id <= alvo.id
+          ))`,
+		notificacaoID, usuarioID, models.NotificacaoCurtida, models.NotificacaoSeguidor,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	return true, nil
+}
+
+// MarcarTodasComoLidas marca todas as notificações do usuário como lidas
+func (repositorio Notificacoes) MarcarTodasComoLidas(usuarioID uint64) error {
+	statement, erro := repositorio.db.Prepare(
+		`UPDATE notificacoes
+        SET lida = TRUE
+        WHERE usuario_id = $1 AND lida = FALSE`,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+func (repositorio Notificacoes) buscarNicks(ids map[int64]bool) (map[int64]string, error) {
+	nicks := make(map[int64]string)
+	if len(ids) == 0 {
+		return nicks, nil
+	}
+
+	lista := make([]int64, 0, len(ids))
+	for id := range ids {
+		lista = append(lista, id)
+	}
+
+	linhas, erro := repositorio.db.Query(
+		`SELECT id, nick FROM usuarios WHERE id = ANY($1)`,
+		pq.Array(lista),
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var (
+			id   int64
+			nick string
+		)
+		if erro = linhas.Scan(&id, &nick); erro != nil {
+			return nil, erro
+		}
+		nicks[id] = nick
+	}
+
+	return nicks, linhas.Err()
+}
+
+// primeirosDistintos retorna até n valores sem repetição, mantendo a ordem original
+func primeirosDistintos(valores []int64, n int) []int64 {
+	vistos := make(map[int64]bool)
+	var resultado []int64
+	for _, valor := range valores {
+		if vistos[valor] {
+			continue
+		}
+		vistos[valor] = true
+		resultado = append(resultado, valor)
+		if len(resultado) == n {
+			break
+		}
+	}
+
+	return resultado
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Outbox representa o repositório dos eventos de domínio esperando para serem entregues
+type Outbox struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeOutbox cria um repositório da outbox
+func NovoRepositorioDeOutbox(db *sql.DB) *Outbox {
+	return &Outbox{db}
+}
+
+// registrarEvento grava um evento de domínio na outbox usando a transação da escrita que o gerou,
+// para que o evento só exista se a escrita for confirmada
+func registrarEvento(transacao *sql.Tx, tipo string, dados interface{}) error {
+	dadosJSON, erro := json.Marshal(dados)
+	if erro != nil {
+		return erro
+	}
+
+	_, erro = transacao.Exec(
+		`INSERT INTO eventos_outbox (tipo, dados)
+        VALUES ($1, $2)`,
+		tipo, string(dadosJSON),
+	)
+	return erro
+}
+
+// ReservarPendentes separa os eventos que já podem ser entregues, contando a tentativa e adiando a próxima
+// pelo tempo de reserva para que outra instância da API não os entregue ao mesmo tempo
+func (repositorio Outbox) ReservarPendentes(limite uint64, reserva time.Duration) ([]models.EventoDominio, error) {
+	linhas, erro := repositorio.db.Query(
+		`UPDATE eventos_outbox
+        SET tentativas = tentativas + 1,
+            proxima_tentativa = CURRENT_TIMESTAMP + make_interval(secs => $2::float8)
+        WHERE id IN (
+            SELECT id FROM eventos_outbox
+            WHERE status = $3 AND proxima_tentativa <= CURRENT_TIMESTAMP
+            ORDER BY id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING id, tipo, dados, tentativas, manipuladores_concluidos, criado_em`,
+		limite, reserva.Seconds(), models.StatusEventoPendente,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var eventos []models.EventoDominio
+	for linhas.Next() {
+		var (
+			evento models.EventoDominio
+			dados  string
+		)
+		if erro = linhas.Scan(
+			&evento.ID,
+			&evento.Tipo,
+			&dados,
+			&evento.Tentativas,
+			pq.Array(&evento.Concluidos),
+			&evento.CriadoEm,
+		); erro != nil {
+			return nil, erro
+		}
+		evento.Dados = json.RawMessage(dados)
+
+		eventos = append(eventos, evento)
+	}
+
+	return eventos, linhas.Err()
+}
+
+// ConcluirManipulador registra que um manipulador já tratou o evento, para que ele não rode de novo numa nova tentativa
+func (repositorio Outbox) ConcluirManipulador(eventoID uint64, manipulador string) error {
+	_, erro := repositorio.db.Exec(
+		`UPDATE eventos_outbox
+        SET manipuladores_concluidos = array_append(manipuladores_concluidos, $2)
+        WHERE id = $1 AND NOT ($2 = ANY(manipuladores_concluidos))`,
+		eventoID, manipulador,
+	)
+	return erro
+}
+
+// MarcarProcessado marca o evento como entregue a todos os manipuladores
+func (repositorio Outbox) MarcarProcessado(eventoID uint64) error {
+	_, erro := repositorio.db.Exec(
+		`UPDATE eventos_outbox
+        SET status = $2, ultimo_erro = NULL, processado_em = CURRENT_TIMESTAMP
+        WHERE id = $1`,
+		eventoID, models.StatusEventoProcessado,
+	)
+	return erro
+}
+
+// RegistrarFalha guarda o erro da tentativa e agenda a próxima, ou desiste do evento quando não houver nova tentativa
+func (repositorio Outbox) RegistrarFalha(eventoID uint64, mensagem string, proximaTentativa time.Duration, desistir bool) error {
+	situacao := models.StatusEventoPendente
+	if desistir {
+		situacao = models.StatusEventoFalhou
+	}
+
+	_, erro := repositorio.db.Exec(
+		`UPDATE eventos_outbox
+        SET status = $2, ultimo_erro = $3,
+            proxima_tentativa = CURRENT_TIMESTAMP + make_interval(secs => $4::float8)
+        WHERE id = $1`,
+		eventoID, situacao, mensagem, proximaTentativa.Seconds(),
+	)
+	return erro
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"api/src/models"
 	"database/sql"
+	"strings"
 )
 
 // Publicacoes representa um repositório de publicações
@@ -15,23 +16,137 @@ func NovoRepositorioDePublicacoes(db *sql.DB) *Publicacoes {
 	return &Publicacoes{db}
 }
 
+// retornoPublicacao devolve a publicação inserida, alterada ou excluída, junto com o nick do autor
+const retornoPublicacao = `RETURNING id, titulo, conteudo, autor_id, curtidas, criado_em,
+            (SELECT nick FROM usuarios WHERE usuarios.id = autor_id)`
+
+// filtroVisibilidade é a condição das listagens fora do feed (hashtags, buscas) sobre a publicação p, de autor u:
+// some quem tem bloqueio com o solicitante ou foi silenciado por ele, e perfis privados só aparecem para
+// seguidores aprovados. O marcador $SOLICITANTE é trocado pelo parâmetro com o ID de quem está vendo.
+const filtroVisibilidade = `NOT EXISTS (
+           SELECT 1 FROM bloqueios b
+           WHERE (b.usuario_id = p.autor_id AND b.bloqueado_id = $SOLICITANTE)
+              OR (b.usuario_id = $SOLICITANTE AND b.bloqueado_id = p.autor_id)
+         )
+         AND NOT EXISTS (
+           SELECT 1 FROM silenciados m
+           WHERE m.usuario_id = $SOLICITANTE
+             AND m.silenciado_id = p.autor_id
+             AND (m.expira_em IS NULL OR m.expira_em > CURRENT_TIMESTAMP)
+         )
+         AND (p.autor_id = $SOLICITANTE OR NOT u.privado OR EXISTS (
+           SELECT 1 FROM seguidores s
+           WHERE s.usuario_id = p.autor_id AND s.seguidor_id = $SOLICITANTE
+         ))`
+
+// visivelPara monta o filtroVisibilidade para o parâmetro informado (por exemplo, "$2")
+func visivelPara(parametro string) string {
+	return strings.ReplaceAll(filtroVisibilidade, "$SOLICITANTE", parametro)
+}
+
+// contemPalavra monta a condição SQL equivalente a models.ContemPalavra: a palavra aparece inteira no texto, sem
+// diferenciar maiúsculas nem acentos. Os caracteres especiais da palavra são escapados antes de ela entrar na
+// expressão regular, e as bordas aceitam o começo e o fim do texto ou qualquer coisa que não seja letra, número ou _.
+func contemPalavra(texto, palavra string) string {
+	return `sem_acento(` + texto + `) ~ ('(^|[^[:alnum:]_])' || ` +
+		`regexp_replace(sem_acento(` + palavra + `), '([^[:alnum:][:space:]_])', '\\\1', 'g') || ` +
+		`'($|[^[:alnum:]_])')`
+}
+
+// escanearPublicacoes lê as linhas de uma listagem no formato
+// id, titulo, conteudo, autor_id, curtidas, criado_em, nick e carrega os detalhes vistos pelo solicitante
+func escanearPublicacoes(db consultor, linhas *sql.Rows, solicitanteID uint64) ([]models.Publicacao, error) {
+	defer linhas.Close()
+
+	var publicacoes []models.Publicacao
+	for linhas.Next() {
+		var publicacao models.Publicacao
+		if erro := linhas.Scan(
+			&publicacao.ID,
+			&publicacao.Titulo,
+			&publicacao.Conteudo,
+			&publicacao.AutorID,
+			&publicacao.Curtidas,
+			&publicacao.CriadaEm,
+			&publicacao.AutorNick,
+		); erro != nil {
+			return nil, erro
+		}
+
+		publicacoes = append(publicacoes, publicacao)
+	}
+	if erro := linhas.Err(); erro != nil {
+		return nil, erro
+	}
+	linhas.Close()
+
+	if erro := carregarDetalhes(db, publicacoes, solicitanteID); erro != nil {
+		return nil, erro
+	}
+
+	return publicacoes, nil
+}
+
+// carregarDetalhes preenche o que fica fora da tabela de publicações: as mídias, as menções, o total de
+// republicações e a publicação citada, que depende do que o solicitante pode ver
+func carregarDetalhes(db consultor, publicacoes []models.Publicacao, solicitanteID uint64) error {
+	if erro := carregarMidias(db, publicacoes); erro != nil {
+		return erro
+	}
+	if erro := carregarMencoes(db, publicacoes); erro != nil {
+		return erro
+	}
+	if erro := carregarRepublicacoes(db, publicacoes); erro != nil {
+		return erro
+	}
+	return carregarCitadas(db, publicacoes, solicitanteID)
+}
+
 // Criar insere uma publicação no banco de dados
 func (repositorio Publicacoes) Criar(publicacao models.Publicacao) (uint64, error) {
-	var id uint64
-	erro := repositorio.db.QueryRow(
-		`INSERT INTO publicacoes (titulo, conteudo, autor_id)
-         VALUES ($1, $2, $3)
-         RETURNING id`,
-		publicacao.Titulo, publicacao.Conteudo, publicacao.AutorID,
-	).Scan(&id)
+	transacao, erro := repositorio.db.Begin()
 	if erro != nil {
 		return 0, erro
 	}
-	return id, nil
+	defer transacao.Rollback()
+
+	midias, citadaID := publicacao.Midias, publicacao.CitadaID
+	publicacao, erro = escanearPublicacaoAlterada(transacao.QueryRow(
+		`INSERT INTO publicacoes (titulo, conteudo, autor_id, citada_id)
+         VALUES ($1, $2, $3, NULLIF($4, 0))
+         `+retornoPublicacao,
+		publicacao.Titulo, publicacao.Conteudo, publicacao.AutorID, publicacao.CitadaID,
+	))
+	if erro != nil {
+		return 0, erro
+	}
+
+	publicacao.CitadaID = citadaID
+
+	if publicacao.Midias, erro = inserirMidias(transacao, publicacao.ID, midias); erro != nil {
+		return 0, erro
+	}
+
+	if erro = salvarHashtags(transacao, publicacao.ID, publicacao.Hashtags()); erro != nil {
+		return 0, erro
+	}
+
+	if publicacao.Mencoes, erro = salvarMencoes(transacao, publicacao); erro != nil {
+		return 0, erro
+	}
+
+	if erro = registrarEvento(transacao, models.EventoPublicacaoCriada, publicacao); erro != nil {
+		return 0, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, erro
+	}
+	return publicacao.ID, nil
 }
 
-// BuscarPorID traz uma única publicação do banco de dados
-func (repositorio Publicacoes) BuscarPorID(publicacaoID uint64) (models.Publicacao, error) {
+// BuscarPorID traz uma única publicação do banco de dados, com a publicação citada vista pelo solicitante
+func (repositorio Publicacoes) BuscarPorID(publicacaoID, solicitanteID uint64) (models.Publicacao, error) {
 	linha, erro := repositorio.db.Query(
 		`SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em AS criadaEm, u.nick
         FROM publicacoes p
@@ -60,23 +175,63 @@ func (repositorio Publicacoes) BuscarPorID(publicacaoID uint64) (models.Publicac
 		}
 	}
 
-	return publicacao, nil
+	if publicacao.ID == 0 {
+		return publicacao, nil
+	}
+
+	publicacoes := []models.Publicacao{publicacao}
+	if erro = carregarDetalhes(repositorio.db, publicacoes, solicitanteID); erro != nil {
+		return models.Publicacao{}, erro
+	}
+
+	return publicacoes[0], nil
 }
 
-// Buscar traz as publicações dos usuários seguidos e também do próprio usuário que fez a requisição
+// Buscar traz o feed: as publicações dos usuários seguidos e do próprio usuário que fez a requisição e as que
+// eles republicaram, sem autores bloqueados ou silenciados e sem publicações que contenham as palavras filtradas.
+// Cada publicação aparece uma vez só: se o original já está no feed, as republicações dele são ignoradas; se não,
+// vale a republicação mais recente. O feed é ordenado pela data da publicação ou da republicação.
 func (repositorio Publicacoes) Buscar(usuarioID uint64) ([]models.Publicacao, error) {
 	linhas, erro := repositorio.db.Query(`
-       SELECT DISTINCT 
-           p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em, u.nick
-       FROM publicacoes p
-       JOIN usuarios u 
-         ON u.id = p.autor_id
-       LEFT JOIN seguidores s
-         ON s.usuario_id = p.autor_id 
-         AND s.seguidor_id = $1
-       WHERE p.autor_id = $1
-          OR s.seguidor_id = $1
-       ORDER BY p.id DESC`,
+       WITH seguidos AS (
+           SELECT usuario_id FROM seguidores WHERE seguidor_id = $1
+           UNION
+           SELECT $1
+       ),
+       itens AS (
+           SELECT DISTINCT ON (publicacao_id) publicacao_id, republicador_id, republicada_em, data_feed
+           FROM (
+               SELECT p.id AS publicacao_id, NULL::INTEGER AS republicador_id,
+                      NULL::TIMESTAMP AS republicada_em, p.criado_em AS data_feed
+               FROM publicacoes p
+               WHERE p.autor_id IN (SELECT usuario_id FROM seguidos)
+               UNION ALL
+               SELECT r.publicacao_id, r.usuario_id, r.criado_em, r.criado_em
+               FROM republicacoes r
+               WHERE r.usuario_id IN (SELECT usuario_id FROM seguidos)
+                 AND NOT EXISTS (
+                   SELECT 1 FROM silenciados m
+                   WHERE m.usuario_id = $1
+                     AND m.silenciado_id = r.usuario_id
+                     AND (m.expira_em IS NULL OR m.expira_em > CURRENT_TIMESTAMP)
+                 )
+           ) candidatos
+           ORDER BY publicacao_id, republicador_id IS NOT NULL, data_feed DESC
+       )
+       SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em, u.nick,
+              i.republicador_id, ru.nick, i.republicada_em
+       FROM itens i
+       JOIN publicacoes p ON p.id = i.publicacao_id
+       JOIN usuarios u ON u.id = p.autor_id
+       LEFT JOIN usuarios ru ON ru.id = i.republicador_id
+       WHERE `+visivelPara("$1")+`
+         AND (p.autor_id = $1 OR NOT EXISTS (
+           SELECT 1 FROM filtros_palavras f
+           WHERE f.usuario_id = $1
+             AND (f.expira_em IS NULL OR f.expira_em > CURRENT_TIMESTAMP)
+             AND (`+contemPalavra("p.titulo", "f.palavra")+` OR `+contemPalavra("p.conteudo", "f.palavra")+`)
+         ))
+       ORDER BY i.data_feed DESC, p.id DESC`,
 		usuarioID,
 	)
 	if erro != nil {
@@ -87,7 +242,12 @@ func (repositorio Publicacoes) Buscar(usuarioID uint64) ([]models.Publicacao, er
 	var publicacoes []models.Publicacao
 
 	for linhas.Next() {
-		var publicacao models.Publicacao
+		var (
+			publicacao       models.Publicacao
+			republicadorID   sql.NullInt64
+			republicadorNick sql.NullString
+			republicadaEm    sql.NullTime
+		)
 		if erro = linhas.Scan(
 			&publicacao.ID,
 			&publicacao.Titulo,
@@ -96,61 +256,138 @@ func (repositorio Publicacoes) Buscar(usuarioID uint64) ([]models.Publicacao, er
 			&publicacao.Curtidas,
 			&publicacao.CriadaEm,
 			&publicacao.AutorNick,
+			&republicadorID,
+			&republicadorNick,
+			&republicadaEm,
 		); erro != nil {
 			return nil, erro
 		}
 
+		if republicadorID.Valid {
+			publicacao.RepublicadaPor = &models.Republicacao{
+				UsuarioID:     uint64(republicadorID.Int64),
+				Nick:          republicadorNick.String,
+				RepublicadaEm: republicadaEm.Time,
+			}
+		}
+
 		publicacoes = append(publicacoes, publicacao)
 	}
 
+	if erro = carregarDetalhes(repositorio.db, publicacoes, usuarioID); erro != nil {
+		return nil, erro
+	}
+
 	return publicacoes, nil
 }
 
 // Atualizar altera os dados de uma publicação no banco de dados
 func (repositorio Publicacoes) Atualizar(publicacaoID uint64, publicacao models.Publicacao) error {
-	statement, erro := repositorio.db.Prepare(
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	publicacao, erro = escanearPublicacaoAlterada(transacao.QueryRow(
 		`UPDATE publicacoes
         SET titulo = $1, conteudo = $2
-        WHERE id = $3`,
-	)
+        WHERE id = $3
+        `+retornoPublicacao,
+		publicacao.Titulo, publicacao.Conteudo, publicacaoID,
+	))
+	if erro == sql.ErrNoRows {
+		return nil
+	}
 	if erro != nil {
 		return erro
 	}
-	defer statement.Close()
 
-	if _, erro = statement.Exec(publicacao.Titulo, publicacao.Conteudo, publicacaoID); erro != nil {
+	if erro = salvarHashtags(transacao, publicacao.ID, publicacao.Hashtags()); erro != nil {
 		return erro
 	}
 
-	return nil
+	if _, erro = salvarMencoes(transacao, publicacao); erro != nil {
+		return erro
+	}
+
+	publicacoes := []models.Publicacao{publicacao}
+	if erro = carregarDetalhes(transacao, publicacoes, publicacao.AutorID); erro != nil {
+		return erro
+	}
+	publicacao = publicacoes[0]
+
+	if erro = registrarEvento(transacao, models.EventoPublicacaoEditada, publicacao); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
 }
 
 // Deletar exclui uma publicação do banco de dados
 func (repositorio Publicacoes) Deletar(publicacaoID uint64) error {
-	statement, erro := repositorio.db.Prepare(
-		`DELETE FROM publicacoes
-        WHERE id = $1`,
-	)
+	transacao, erro := repositorio.db.Begin()
 	if erro != nil {
 		return erro
 	}
-	defer statement.Close()
+	defer transacao.Rollback()
 
-	if _, erro = statement.Exec(publicacaoID); erro != nil {
+	if erro = deletarPublicacao(transacao, publicacaoID); erro != nil {
 		return erro
 	}
 
-	return nil
+	return transacao.Commit()
 }
 
-// BuscarPorUsuario traz as publicações de um usuário específico
-func (repositorio Publicacoes) BuscarPorUsuario(usuarioID uint64) ([]models.Publicacao, error) {
+// deletarPublicacao exclui a publicação dentro da transação e registra o evento que apaga os arquivos das mídias.
+// Uma publicação que já não existe é ignorada.
+func deletarPublicacao(transacao *sql.Tx, publicacaoID uint64) error {
+	// As mídias são carregadas antes da exclusão em cascata para que os arquivos possam ser apagados depois
+	publicacoes := []models.Publicacao{{ID: publicacaoID}}
+	if erro := carregarMidias(transacao, publicacoes); erro != nil {
+		return erro
+	}
+
+	publicacao, erro := escanearPublicacaoAlterada(transacao.QueryRow(
+		`DELETE FROM publicacoes
+        WHERE id = $1
+        `+retornoPublicacao,
+		publicacaoID,
+	))
+	if erro == sql.ErrNoRows {
+		return nil
+	}
+	if erro != nil {
+		return erro
+	}
+
+	excluida := models.PublicacaoExcluida{Publicacao: publicacao}
+	excluida.Midias = publicacoes[0].Midias
+	for _, midia := range excluida.Midias {
+		excluida.ChavesMidias = append(excluida.ChavesMidias, midia.Chaves()...)
+	}
+
+	return registrarEvento(transacao, models.EventoPublicacaoExcluida, excluida)
+}
+
+// BuscarPorUsuario traz as publicações de um usuário específico, vazio se houver bloqueio entre ele e o solicitante
+// ou se o perfil for privado e o solicitante não for um seguidor aprovado
+func (repositorio Publicacoes) BuscarPorUsuario(usuarioID, solicitanteID uint64) ([]models.Publicacao, error) {
 	linhas, erro := repositorio.db.Query(
 		`SELECT p.id, p.titulo, p.conteudo, p.autor_id, p.curtidas, p.criado_em AS criadaEm, u.nick
         FROM publicacoes p
         JOIN usuarios u ON u.id = p.autor_id
-        WHERE p.autor_id = $1`,
-		usuarioID,
+        WHERE p.autor_id = $1
+          AND NOT EXISTS (
+            SELECT 1 FROM bloqueios b
+            WHERE (b.usuario_id = p.autor_id AND b.bloqueado_id = $2)
+               OR (b.usuario_id = $2 AND b.bloqueado_id = p.autor_id)
+          )
+          AND (p.autor_id = $2 OR NOT u.privado OR EXISTS (
+            SELECT 1 FROM seguidores s
+            WHERE s.usuario_id = p.autor_id AND s.seguidor_id = $2
+          ))`,
+		usuarioID, solicitanteID,
 	)
 
 	if erro != nil {
@@ -178,39 +415,85 @@ func (repositorio Publicacoes) BuscarPorUsuario(usuarioID uint64) ([]models.Publ
 		publicacoes = append(publicacoes, publicacao)
 	}
 
+	if erro = carregarDetalhes(repositorio.db, publicacoes, solicitanteID); erro != nil {
+		return nil, erro
+	}
+
 	return publicacoes, nil
 }
 
-// Curtir adiciona uma curtida a uma publicação
-func (repositorio Publicacoes) Curtir(publicacaoID uint64) error {
-	statement, erro := repositorio.db.Prepare(
-		`UPDATE publicacoes
+// Curtir adiciona uma curtida do usuário a uma publicação. Curtir de novo uma publicação já curtida não faz nada.
+func (repositorio Publicacoes) Curtir(publicacaoID, usuarioID uint64) error {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	curtida := models.Curtida{PublicacaoID: publicacaoID, UsuarioID: usuarioID}
+	erro = transacao.QueryRow(
+		`WITH nova AS (
+           INSERT INTO curtidas (usuario_id, publicacao_id)
+           SELECT $2, id FROM publicacoes WHERE id = $1
+           ON CONFLICT DO NOTHING
+           RETURNING publicacao_id
+        )
+        UPDATE publicacoes
         SET curtidas = curtidas + 1
-        WHERE id = $1`,
-	)
+        WHERE id IN (SELECT publicacao_id FROM nova)
+        RETURNING autor_id`,
+		publicacaoID, usuarioID,
+	).Scan(&curtida.AutorID)
+	if erro == sql.ErrNoRows {
+		return nil
+	}
 	if erro != nil {
 		return erro
 	}
-	defer statement.Close()
 
-	if _, erro = statement.Exec(publicacaoID); erro != nil {
+	if erro = registrarEvento(transacao, models.EventoPublicacaoCurtida, curtida); erro != nil {
 		return erro
 	}
-	return nil
+
+	return transacao.Commit()
 }
 
-// Descurtir subtrai uma curtida a uma publicação
-func (repositorio Publicacoes) Descurtir(publicacaoID uint64) error {
+// Descurtir tira a curtida do usuário de uma publicação, sem fazer nada se ele não a tiver curtido
+func (repositorio Publicacoes) Descurtir(publicacaoID, usuarioID uint64) error {
 	statement, erro := repositorio.db.Prepare(
-		`UPDATE publicacoes
+		`WITH removida AS (
+           DELETE FROM curtidas
+           WHERE publicacao_id = $1 AND usuario_id = $2
+           RETURNING publicacao_id
+        )
+        UPDATE publicacoes
         SET curtidas = CASE WHEN curtidas > 0 THEN curtidas - 1 ELSE 0 END
-        WHERE id = $1`,
+        WHERE id IN (SELECT publicacao_id FROM removida)`,
 	)
 	if erro != nil {
 		return erro
 	}
-	if _, erro = statement.Exec(publicacaoID); erro != nil {
+	defer statement.Close()
+
+	if _, erro = statement.Exec(publicacaoID, usuarioID); erro != nil {
 		return erro
 	}
 	return nil
 }
+
+func escanearPublicacaoAlterada(linha *sql.Row) (models.Publicacao, error) {
+	var publicacao models.Publicacao
+	if erro := linha.Scan(
+		&publicacao.ID,
+		&publicacao.Titulo,
+		&publicacao.Conteudo,
+		&publicacao.AutorID,
+		&publicacao.Curtidas,
+		&publicacao.CriadaEm,
+		&publicacao.AutorNick,
+	); erro != nil {
+		return models.Publicacao{}, erro
+	}
+
+	return publicacao, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RedefinicoesDeSenha representa um repositório de tokens de redefinição de senha
+type RedefinicoesDeSenha struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeRedefinicoesDeSenha cria um repositório de redefinições de senha
+func NovoRepositorioDeRedefinicoesDeSenha(db *sql.DB) *RedefinicoesDeSenha {
+	return &RedefinicoesDeSenha{db}
+}
+
+// Criar invalida os tokens pendentes do usuário e salva o hash de um novo token
+func (repositorio RedefinicoesDeSenha) Criar(usuarioID uint64, tokenHash string, validade time.Duration) error {
+	return criarTokenUsoUnico(repositorio.db, tabelaRedefinicoesSenha, usuarioID, tokenHash, validade)
+}
+
+// Redefinir marca o token como usado, troca a senha do usuário dono dele e encerra as sessões abertas, desde que o
+// token ainda esteja válido. Tudo acontece na mesma transação, então uma falha não gasta o token sem trocar a senha.
+func (repositorio RedefinicoesDeSenha) Redefinir(tokenHash, senhaComHash string) error {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	usuarioID, erro := consumirTokenUsoUnico(transacao, tabelaRedefinicoesSenha, tokenHash)
+	if erro != nil {
+		return erro
+	}
+
+	if erro = atualizarSenha(transacao, usuarioID, senhaComHash); erro != nil {
+		return erro
+	}
+
+	// Quem pediu a redefinição pode estar tirando o acesso de alguém que conhecia a senha antiga
+	if erro = encerrarSessoes(transacao, usuarioID); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
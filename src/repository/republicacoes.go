@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"api/src/models"
+
+	"github.com/lib/pq"
+)
+
+// Republicar compartilha a publicação com os seguidores do usuário. Republicar de novo não tem efeito.
+func (repositorio Publicacoes) Republicar(publicacaoID, usuarioID uint64) error {
+	_, erro := repositorio.db.Exec(
+		`INSERT INTO republicacoes (usuario_id, publicacao_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING`,
+		usuarioID, publicacaoID,
+	)
+	return erro
+}
+
+// DesfazerRepublicacao tira a republicação do usuário, se houver
+func (repositorio Publicacoes) DesfazerRepublicacao(publicacaoID, usuarioID uint64) error {
+	_, erro := repositorio.db.Exec(
+		`DELETE FROM republicacoes
+        WHERE usuario_id = $1 AND publicacao_id = $2`,
+		usuarioID, publicacaoID,
+	)
+	return erro
+}
+
+// carregarRepublicacoes preenche quantas vezes cada publicação foi republicada
+func carregarRepublicacoes(db consultor, publicacoes []models.Publicacao) error {
+	if len(publicacoes) == 0 {
+		return nil
+	}
+
+	posicoes := make(map[int64][]int, len(publicacoes))
+	IDs := make([]int64, 0, len(publicacoes))
+	for i, publicacao := range publicacoes {
+		posicoes[int64(publicacao.ID)] = append(posicoes[int64(publicacao.ID)], i)
+		IDs = append(IDs, int64(publicacao.ID))
+	}
+
+	linhas, erro := db.Query(
+		`SELECT publicacao_id, COUNT(*)
+        FROM republicacoes
+        WHERE publicacao_id = ANY($1)
+        GROUP BY publicacao_id`,
+		pq.Array(IDs),
+	)
+	if erro != nil {
+		return erro
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var (
+			publicacaoID int64
+			total        uint64
+		)
+		if erro = linhas.Scan(&publicacaoID, &total); erro != nil {
+			return erro
+		}
+
+		for _, i := range posicoes[publicacaoID] {
+			publicacoes[i].Republicacoes = total
+		}
+	}
+
+	return linhas.Err()
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrTokenInvalido indica que o token não existe, já foi usado ou expirou
+var ErrTokenInvalido = errors.New("token inválido ou expirado")
+
+// Tabelas de tokens de uso único. Todas têm as colunas usuario_id, token_hash, expira_em e usado_em; o nome entra
+// direto no SQL, então só estas constantes devem ser usadas.
+const (
+	tabelaRedefinicoesSenha = "redefinicoes_senha"
+	tabelaVerificacoesEmail = "verificacoes_email"
+)
+
+// criarTokenUsoUnico invalida os tokens pendentes do usuário na tabela e salva o hash de um novo token
+func criarTokenUsoUnico(db *sql.DB, tabela string, usuarioID uint64, tokenHash string, validade time.Duration) error {
+	transacao, erro := db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(
+		`UPDATE `+tabela+`
+        SET usado_em = CURRENT_TIMESTAMP
+        WHERE usuario_id = $1 AND usado_em IS NULL`,
+		usuarioID,
+	); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(
+		`INSERT INTO `+tabela+` (usuario_id, token_hash, expira_em)
+        VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3))`,
+		usuarioID, tokenHash, validade.Seconds(),
+	); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// consumirTokenUsoUnico marca, dentro da transação, o token como usado e retorna o usuário dono dele. Tokens que
+// não existem, já foram usados ou expiraram retornam ErrTokenInvalido.
+func consumirTokenUsoUnico(transacao *sql.Tx, tabela, tokenHash string) (uint64, error) {
+	var usuarioID uint64
+	erro := transacao.QueryRow(
+		`UPDATE `+tabela+`
+        SET usado_em = CURRENT_TIMESTAMP
+        WHERE token_hash = $1
+          AND usado_em IS NULL
+          AND expira_em > CURRENT_TIMESTAMP
+        RETURNING usuario_id`,
+		tokenHash,
+	).Scan(&usuarioID)
+	if erro == sql.ErrNoRows {
+		return 0, ErrTokenInvalido
+	}
+	if erro != nil {
+		return 0, erro
+	}
+
+	return usuarioID, nil
+}
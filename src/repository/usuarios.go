@@ -4,6 +4,10 @@ import (
 	"api/src/models"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // Usuarios representa um repositório de usuarios
@@ -18,29 +22,61 @@ func NovoRepositorioDeUsuarios(db *sql.DB) *Usuarios {
 
 // Criar insere um usuário no banco de dados
 func (repositorio Usuarios) Criar(usuario models.Usuario) (uint64, error) {
-	var id uint64
-	erro := repositorio.db.QueryRow(
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return 0, erro
+	}
+	defer transacao.Rollback()
+
+	criado := models.Usuario{Nome: usuario.Nome, Nick: usuario.Nick, Email: usuario.Email}
+	erro = transacao.QueryRow(
 		`INSERT INTO usuarios (nome, nick, email, senha)
           VALUES ($1, $2, $3, $4)
-          RETURNING id`,
+          RETURNING id, criado_em, papel`,
 		usuario.Nome, usuario.Nick, usuario.Email, usuario.Senha,
-	).Scan(&id)
+	).Scan(&criado.ID, &criado.CriadoEm, &criado.Papel)
 	if erro != nil {
 		return 0, erro
 	}
-	return id, nil
 
-}
+	if erro = registrarEvento(transacao, models.EventoUsuarioCriado, criado); erro != nil {
+		return 0, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, erro
+	}
+	return criado.ID, nil
 
-// Buscar traz todos os usuários que atendem um filtro de nome ou nick
-func (repositorio Usuarios) Buscar(nomeOuNick string) ([]models.Usuario, error) {
-	nomeOuNick = fmt.Sprintf("%%%s%%", nomeOuNick) //%nomeOuNick%
+}
 
+// Buscar traz os usuários cujo nome ou nick se parecem com o termo, sem diferenciar maiúsculas nem acentos
+// ("joao" acha "João") e tolerando pequenos erros de digitação, sem os que têm bloqueio com o solicitante.
+// O nick idêntico ao termo vem primeiro, depois os nicks que começam com ele e então os mais parecidos.
+func (repositorio Usuarios) Buscar(termo string, solicitanteID, limite, deslocamento uint64) ([]models.Usuario, error) {
 	linhas, erro := repositorio.db.Query(
-		`SELECT id, nome, nick, email, criado_em AS criadoEm
-         FROM usuarios
-         WHERE nome LIKE $1 OR nick LIKE $2`,
-		nomeOuNick, nomeOuNick,
+		`WITH busca AS (SELECT sem_acento($1) AS termo, '%' || sem_acento($2) || '%' AS padrao)
+        SELECT u.id, u.nome, u.nick, u.bio, u.criado_em AS criadoEm
+        FROM usuarios u
+        CROSS JOIN busca
+        WHERE (busca.termo <% sem_acento(u.nome)
+            OR sem_acento(u.nick) % busca.termo
+            OR sem_acento(u.nome) LIKE busca.padrao
+            OR sem_acento(u.nick) LIKE busca.padrao)
+          AND NOT EXISTS (
+            SELECT 1 FROM bloqueios b
+            WHERE (b.usuario_id = u.id AND b.bloqueado_id = $3)
+               OR (b.usuario_id = $3 AND b.bloqueado_id = u.id)
+          )
+        ORDER BY sem_acento(u.nick) = busca.termo DESC,
+                 starts_with(sem_acento(u.nick), busca.termo) DESC,
+                 GREATEST(
+                   similarity(sem_acento(u.nick), busca.termo),
+                   word_similarity(busca.termo, sem_acento(u.nome))
+                 ) DESC,
+                 u.nick
+        LIMIT $4 OFFSET $5`,
+		termo, escaparLike(termo), solicitanteID, limite, deslocamento,
 	)
 	if erro != nil {
 		return nil, erro
@@ -57,7 +93,7 @@ func (repositorio Usuarios) Buscar(nomeOuNick string) ([]models.Usuario, error)
 			&usuario.ID,
 			&usuario.Nome,
 			&usuario.Nick,
-			&usuario.Email,
+			&usuario.Bio,
 			&usuario.CriadoEm,
 		); erro != nil {
 			return nil, erro
@@ -66,13 +102,19 @@ func (repositorio Usuarios) Buscar(nomeOuNick string) ([]models.Usuario, error)
 		usuarios = append(usuarios, usuario)
 	}
 
-	return usuarios, nil
+	return usuarios, linhas.Err()
 }
 
-// BuscarPorId traz um usuário do banco de dados
+// escaparLike escapa os curingas do LIKE (% e _) para que o termo seja buscado literalmente
+func escaparLike(termo string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(termo)
+}
+
+// BuscarPorId traz um usuário do banco de dados, com o perfil e as imagens de avatar e banner
 func (repositorio Usuarios) BuscarPorId(ID uint64) (models.Usuario, error) {
 	linhas, erro := repositorio.db.Query(
-		`SELECT id, nome, nick, email, criado_em AS criadoEm
+		`SELECT id, nome, nick, email, criado_em AS criadoEm, email_verificado, papel, privado,
+                bio, website, localizacao, avatar_id, banner_id
         FROM usuarios
         WHERE id = $1`,
 		ID,
@@ -82,7 +124,10 @@ func (repositorio Usuarios) BuscarPorId(ID uint64) (models.Usuario, error) {
 	}
 	defer linhas.Close()
 
-	var usuario models.Usuario
+	var (
+		usuario            models.Usuario
+		avatarID, bannerID sql.NullInt64
+	)
 
 	if linhas.Next() {
 		if erro = linhas.Scan(
@@ -91,52 +136,181 @@ func (repositorio Usuarios) BuscarPorId(ID uint64) (models.Usuario, error) {
 			&usuario.Nick,
 			&usuario.Email,
 			&usuario.CriadoEm,
+			&usuario.EmailVerificado,
+			&usuario.Papel,
+			&usuario.Privado,
+			&usuario.Bio,
+			&usuario.Website,
+			&usuario.Localizacao,
+			&avatarID,
+			&bannerID,
 		); erro != nil {
 			return models.Usuario{}, erro
 		}
 	}
+	linhas.Close()
+
+	var IDs []int64
+	for _, ID := range []sql.NullInt64{avatarID, bannerID} {
+		if ID.Valid {
+			IDs = append(IDs, ID.Int64)
+		}
+	}
+
+	midias, erro := buscarMidiasPorID(repositorio.db, IDs)
+	if erro != nil {
+		return models.Usuario{}, erro
+	}
+	usuario.Avatar = midias[avatarID.Int64]
+	usuario.Banner = midias[bannerID.Int64]
+
 	return usuario, nil
 }
 
+// BuscarPerfil traz o usuário com os totais de seguidores, seguindo e publicações e a relação dele com o solicitante.
+// Retorna um perfil vazio (ID 0) se o usuário não existir.
+func (repositorio Usuarios) BuscarPerfil(usuarioID, solicitanteID uint64) (models.Perfil, error) {
+	usuario, erro := repositorio.BuscarPorId(usuarioID)
+	if erro != nil || usuario.ID == 0 {
+		return models.Perfil{}, erro
+	}
+
+	perfil := models.Perfil{Usuario: usuario}
+	if erro = repositorio.db.QueryRow(
+		`SELECT
+            (SELECT COUNT(*) FROM seguidores WHERE usuario_id = $1),
+            (SELECT COUNT(*) FROM seguidores WHERE seguidor_id = $1),
+            (SELECT COUNT(*) FROM publicacoes WHERE autor_id = $1),
+            EXISTS (SELECT 1 FROM seguidores WHERE usuario_id = $1 AND seguidor_id = $2),
+            EXISTS (SELECT 1 FROM seguidores WHERE usuario_id = $2 AND seguidor_id = $1),
+            EXISTS (SELECT 1 FROM solicitacoes_seguir WHERE usuario_id = $1 AND solicitante_id = $2)`,
+		usuarioID, solicitanteID,
+	).Scan(
+		&perfil.Seguidores,
+		&perfil.Seguindo,
+		&perfil.Publicacoes,
+		&perfil.VoceSegue,
+		&perfil.SegueVoce,
+		&perfil.SolicitacaoPendente,
+	); erro != nil {
+		return models.Perfil{}, erro
+	}
+
+	return perfil, nil
+}
+
 // Atualizar altera as informações de um usuário no banco de dados
 func (repositorio Usuarios) Atualizar(ID uint64, usuario models.Usuario) error {
 	statement, erro := repositorio.db.Prepare(
 		`UPDATE usuarios
-        SET nome = $1, nick = $2, email = $3
-        WHERE id = $4`,
+        SET nome = $1, nick = $2, email = $3,
+            email_verificado = email_verificado AND email = $3,
+            bio = $4, website = $5, localizacao = $6
+        WHERE id = $7`,
 	)
 	if erro != nil {
 		return erro
 	}
 	defer statement.Close()
 
-	if _, erro = statement.Exec(usuario.Nome, usuario.Nick, usuario.Email, ID); erro != nil {
+	if _, erro = statement.Exec(
+		usuario.Nome, usuario.Nick, usuario.Email, usuario.Bio, usuario.Website, usuario.Localizacao, ID,
+	); erro != nil {
 		return erro
 	}
 	return nil
 }
 
-// Deletar exclui as informações de um usuário no banco de dados
+// AtualizarImagemPerfil troca o avatar ou o banner do usuário, gravando a nova mídia (ou nenhuma, se for nil)
+// e descartando a anterior na mesma transação. Retorna false se o usuário não existir.
+func (repositorio Usuarios) AtualizarImagemPerfil(usuarioID uint64, imagem string, midia *models.Midia) (bool, error) {
+	if imagem != models.ImagemPerfilAvatar && imagem != models.ImagemPerfilBanner {
+		return false, fmt.Errorf("imagem de perfil desconhecida: %s", imagem)
+	}
+	coluna := imagem + "_id"
+
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+	defer transacao.Rollback()
+
+	var anterior sql.NullInt64
+	if erro = transacao.QueryRow(
+		`SELECT `+coluna+` FROM usuarios WHERE id = $1 FOR UPDATE`,
+		usuarioID,
+	).Scan(&anterior); erro == sql.ErrNoRows {
+		return false, nil
+	} else if erro != nil {
+		return false, erro
+	}
+
+	var nova sql.NullInt64
+	if midia != nil {
+		if erro = inserirMidia(transacao, sql.NullInt64{}, 0, midia); erro != nil {
+			return false, erro
+		}
+		nova = sql.NullInt64{Int64: int64(midia.ID), Valid: true}
+	}
+
+	if _, erro = transacao.Exec(
+		`UPDATE usuarios SET `+coluna+` = $1 WHERE id = $2`,
+		nova, usuarioID,
+	); erro != nil {
+		return false, erro
+	}
+
+	if anterior.Valid {
+		if erro = descartarMidias(transacao, []int64{anterior.Int64}); erro != nil {
+			return false, erro
+		}
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return false, erro
+	}
+	return true, nil
+}
+
+// Deletar exclui as informações de um usuário no banco de dados, descartando as imagens do perfil
+// e das publicações dele
 func (repositorio Usuarios) Deletar(ID uint64) error {
-	statement, erro := repositorio.db.Prepare(
-		`DELETE FROM usuarios
-        WHERE id = $1`,
-	)
+	transacao, erro := repositorio.db.Begin()
 	if erro != nil {
 		return erro
 	}
-	defer statement.Close()
+	defer transacao.Rollback()
 
-	if _, erro = statement.Exec(ID); erro != nil {
+	var midias pq.Int64Array
+	if erro = transacao.QueryRow(
+		`SELECT COALESCE(array_agg(id), '{}') FROM (
+            SELECT m.id FROM midias m
+            INNER JOIN publicacoes p ON p.id = m.publicacao_id
+            WHERE p.autor_id = $1
+            UNION
+            SELECT unnest(ARRAY[avatar_id, banner_id]) FROM usuarios WHERE id = $1
+        ) m
+        WHERE id IS NOT NULL`,
+		ID,
+	).Scan(&midias); erro != nil {
 		return erro
 	}
-	return nil
+
+	if erro = descartarMidias(transacao, midias); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(`DELETE FROM usuarios WHERE id = $1`, ID); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
 }
 
-// BuscarPorEmail busca um usuário por email e retorna seu ID e senha com hash
+// BuscarPorEmail busca um usuário por email e retorna seu ID, senha com hash, papel, se está suspenso e a versão da sessão
 func (repositorio Usuarios) BuscarPorEmail(email string) (models.Usuario, error) {
 	linha, erro := repositorio.db.Query(
-		`SELECT id, senha
+		`SELECT id, senha, papel, suspenso, versao_sessao
         FROM usuarios
         WHERE email = $1`,
 		email,
@@ -149,7 +323,7 @@ func (repositorio Usuarios) BuscarPorEmail(email string) (models.Usuario, error)
 	var usuario models.Usuario
 
 	if linha.Next() {
-		if erro = linha.Scan(&usuario.ID, &usuario.Senha); erro != nil {
+		if erro = linha.Scan(&usuario.ID, &usuario.Senha, &usuario.Papel, &usuario.Suspenso, &usuario.VersaoSessao); erro != nil {
 			return models.Usuario{}, erro
 		}
 	}
@@ -159,45 +333,66 @@ func (repositorio Usuarios) BuscarPorEmail(email string) (models.Usuario, error)
 
 // Seguir permite quem um usuário siga outro
 func (repositorio Usuarios) Seguir(usuarioID, seguidorID uint64) error {
-	statement, erro := repositorio.db.Prepare(
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(
 		`INSERT INTO seguidores (usuario_id, seguidor_id)
        	VALUES ($1, $2)
         ON CONFLICT (usuario_id, seguidor_id) DO NOTHING`,
+		usuarioID, seguidorID,
 	)
 	if erro != nil {
 		return erro
 	}
-	defer statement.Close()
 
-	if _, erro = statement.Exec(usuarioID, seguidorID); erro != nil {
+	// Seguir alguém que já é seguido não gera um novo evento
+	if linhas, erro := resultado.RowsAffected(); erro != nil || linhas == 0 {
 		return erro
 	}
 
-	return nil
+	seguimento := models.Seguimento{UsuarioID: usuarioID, SeguidorID: seguidorID}
+	if erro = registrarEvento(transacao, models.EventoUsuarioSeguido, seguimento); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
 }
 
-// PararDeSeguir permite quem um usuário pare de seguir o outro
+// PararDeSeguir permite quem um usuário pare de seguir o outro, cancelando também um pedido pendente
 func (repositorio Usuarios) PararDeSeguir(usuarioID, seguidorID uint64) error {
-	statement, erro := repositorio.db.Prepare(
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(
 		`DELETE FROM seguidores
         WHERE usuario_id = $1 AND seguidor_id = $2`,
-	)
-	if erro != nil {
+		usuarioID, seguidorID,
+	); erro != nil {
 		return erro
 	}
-	defer statement.Close()
 
-	if _, erro = statement.Exec(usuarioID, seguidorID); erro != nil {
+	if _, erro = transacao.Exec(
+		`DELETE FROM solicitacoes_seguir
+        WHERE usuario_id = $1 AND solicitante_id = $2`,
+		usuarioID, seguidorID,
+	); erro != nil {
 		return erro
 	}
 
-	return nil
+	return transacao.Commit()
 }
 
 // BuscarSeguidores traz todos os seguidores de um usuário
 func (repositorio Usuarios) BuscarSeguidores(usuarioID uint64) ([]models.Usuario, error) {
 	linhas, erro := repositorio.db.Query(
-		`SELECT u.id, u.nome, u.nick, u.email, u.criado_em AS criadoEm
+		`SELECT u.id, u.nome, u.nick, u.bio, u.criado_em AS criadoEm
         FROM usuarios u
         INNER JOIN seguidores s ON u.id = s.seguidor_id
         WHERE s.usuario_id = $1`,
@@ -216,7 +411,7 @@ func (repositorio Usuarios) BuscarSeguidores(usuarioID uint64) ([]models.Usuario
 			&usuario.ID,
 			&usuario.Nome,
 			&usuario.Nick,
-			&usuario.Email,
+			&usuario.Bio,
 			&usuario.CriadoEm,
 		); erro != nil {
 			return nil, erro
@@ -229,11 +424,48 @@ func (repositorio Usuarios) BuscarSeguidores(usuarioID uint64) ([]models.Usuario
 
 }
 
+// BuscarFiltrosDeSeguidores traz quem segue o usuário e não o silenciou, cada um com as palavras dos seus
+// filtros ativos (vazio para quem não tem nenhum)
+func (repositorio Usuarios) BuscarFiltrosDeSeguidores(usuarioID uint64) (map[uint64][]string, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT s.seguidor_id, COALESCE(array_agg(f.palavra) FILTER (WHERE f.id IS NOT NULL), '{}')
+        FROM seguidores s
+        LEFT JOIN filtros_palavras f ON f.usuario_id = s.seguidor_id
+          AND (f.expira_em IS NULL OR f.expira_em > CURRENT_TIMESTAMP)
+        WHERE s.usuario_id = $1
+          AND NOT EXISTS (
+            SELECT 1 FROM silenciados m
+            WHERE m.usuario_id = s.seguidor_id AND m.silenciado_id = s.usuario_id
+              AND (m.expira_em IS NULL OR m.expira_em > CURRENT_TIMESTAMP)
+          )
+        GROUP BY s.seguidor_id`,
+		usuarioID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	filtros := make(map[uint64][]string)
+	for linhas.Next() {
+		var (
+			ID       uint64
+			palavras []string
+		)
+		if erro = linhas.Scan(&ID, pq.Array(&palavras)); erro != nil {
+			return nil, erro
+		}
+		filtros[ID] = palavras
+	}
+
+	return filtros, linhas.Err()
+}
+
 // BuscarSeguindo traz todos os usuários que um usuário está seguindo
 func (repositorio Usuarios) BuscarSeguindo(usuarioID uint64) ([]models.Usuario, error) {
 
 	linhas, erro := repositorio.db.Query(
-		`SELECT u.id, u.nome, u.nick, u.email, u.criado_em AS criadoEm
+		`SELECT u.id, u.nome, u.nick, u.bio, u.criado_em AS criadoEm
         FROM usuarios u
         INNER JOIN seguidores s ON u.id = s.usuario_id
         WHERE s.seguidor_id = $1`,
@@ -253,7 +485,7 @@ func (repositorio Usuarios) BuscarSeguindo(usuarioID uint64) ([]models.Usuario,
 			&usuario.ID,
 			&usuario.Nome,
 			&usuario.Nick,
-			&usuario.Email,
+			&usuario.Bio,
 			&usuario.CriadoEm,
 		); erro != nil {
 			return nil, erro
@@ -291,18 +523,564 @@ func (repositorio Usuarios) BuscarSenha(usuarioID uint64) (string, error) {
 
 // AtualizarSenha altera a senha de um usuário no banco de dados
 func (repositorio Usuarios) AtualizarSenha(usuarioID uint64, senha string) error {
-	statement, erro := repositorio.db.Prepare(
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if erro = atualizarSenha(transacao, usuarioID, senha); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// atualizarSenha grava, dentro da transação, o novo hash da senha do usuário
+func atualizarSenha(transacao *sql.Tx, usuarioID uint64, senha string) error {
+	_, erro := transacao.Exec(
 		`UPDATE usuarios
         SET senha = $1
         WHERE id = $2`,
+		senha, usuarioID,
+	)
+	return erro
+}
+
+// encerrarSessoes invalida, dentro da transação, todos os tokens já emitidos para o usuário
+func encerrarSessoes(transacao *sql.Tx, usuarioID uint64) error {
+	_, erro := transacao.Exec(
+		`UPDATE usuarios
+        SET versao_sessao = versao_sessao + 1
+        WHERE id = $1`,
+		usuarioID,
+	)
+	return erro
+}
+
+// EmailVerificado informa se o usuário já confirmou o seu email
+func (repositorio Usuarios) EmailVerificado(usuarioID uint64) (bool, error) {
+	var verificado bool
+	erro := repositorio.db.QueryRow(
+		`SELECT email_verificado
+        FROM usuarios
+        WHERE id = $1`,
+		usuarioID,
+	).Scan(&verificado)
+	if erro == sql.ErrNoRows {
+		return false, nil
+	}
+	if erro != nil {
+		return false, erro
+	}
+
+	return verificado, nil
+}
+
+// BuscarSessao traz o que é conferido a cada requisição autenticada: o papel, a suspensão e a versão da sessão
+// do usuário. Um usuário que não existe mais volta sem ID.
+func (repositorio Usuarios) BuscarSessao(usuarioID uint64) (models.Usuario, error) {
+	var usuario models.Usuario
+	erro := repositorio.db.QueryRow(
+		`SELECT id, papel, suspenso, versao_sessao
+        FROM usuarios
+        WHERE id = $1`,
+		usuarioID,
+	).Scan(&usuario.ID, &usuario.Papel, &usuario.Suspenso, &usuario.VersaoSessao)
+	if erro == sql.ErrNoRows {
+		return models.Usuario{}, nil
+	}
+	if erro != nil {
+		return models.Usuario{}, erro
+	}
+
+	return usuario, nil
+}
+
+// AtualizarPapel altera o papel de um usuário no banco de dados e informa se o usuário existia
+func (repositorio Usuarios) AtualizarPapel(usuarioID uint64, papel string) (bool, error) {
+	statement, erro := repositorio.db.Prepare(
+		`UPDATE usuarios
+        SET papel = $1
+        WHERE id = $2`,
+	)
+	if erro != nil {
+		return false, erro
+	}
+	defer statement.Close()
+
+	resultado, erro := statement.Exec(papel, usuarioID)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+
+	return linhas > 0, nil
+}
+
+// suspenderUsuario impede, dentro da transação, que o usuário continue acessando a API
+func suspenderUsuario(transacao *sql.Tx, usuarioID uint64) error {
+	_, erro := transacao.Exec(
+		`UPDATE usuarios
+        SET suspenso = TRUE
+        WHERE id = $1`,
+		usuarioID,
+	)
+	return erro
+}
+
+// Bloquear registra o bloqueio e desfaz as relações de seguidor nos dois sentidos
+func (repositorio Usuarios) Bloquear(usuarioID, bloqueadoID uint64) error {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(
+		`INSERT INTO bloqueios (usuario_id, bloqueado_id)
+        VALUES ($1, $2)
+        ON CONFLICT (usuario_id, bloqueado_id) DO NOTHING`,
+		usuarioID, bloqueadoID,
+	); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(
+		`DELETE FROM seguidores
+        WHERE (usuario_id = $1 AND seguidor_id = $2)
+           OR (usuario_id = $2 AND seguidor_id = $1)`,
+		usuarioID, bloqueadoID,
+	); erro != nil {
+		return erro
+	}
+
+	if _, erro = transacao.Exec(
+		`DELETE FROM solicitacoes_seguir
+        WHERE (usuario_id = $1 AND solicitante_id = $2)
+           OR (usuario_id = $2 AND solicitante_id = $1)`,
+		usuarioID, bloqueadoID,
+	); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// Desbloquear remove o bloqueio que um usuário fez sobre outro
+func (repositorio Usuarios) Desbloquear(usuarioID, bloqueadoID uint64) error {
+	statement, erro := repositorio.db.Prepare(
+		`DELETE FROM bloqueios
+        WHERE usuario_id = $1 AND bloqueado_id = $2`,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, bloqueadoID); erro != nil {
+		return erro
+	}
+	return nil
+}
+
+// ExisteBloqueio informa se algum dos dois usuários bloqueou o outro
+func (repositorio Usuarios) ExisteBloqueio(usuarioID, outroUsuarioID uint64) (bool, error) {
+	var existe bool
+	erro := repositorio.db.QueryRow(
+		`SELECT EXISTS (
+           SELECT 1 FROM bloqueios
+           WHERE (usuario_id = $1 AND bloqueado_id = $2)
+              OR (usuario_id = $2 AND bloqueado_id = $1)
+        )`,
+		usuarioID, outroUsuarioID,
+	).Scan(&existe)
+	if erro != nil {
+		return false, erro
+	}
+
+	return existe, nil
+}
+
+// Segue informa se o seguidor já segue o usuário
+func (repositorio Usuarios) Segue(usuarioID, seguidorID uint64) (bool, error) {
+	var segue bool
+	erro := repositorio.db.QueryRow(
+		`SELECT EXISTS (
+           SELECT 1 FROM seguidores
+           WHERE usuario_id = $1 AND seguidor_id = $2
+        )`,
+		usuarioID, seguidorID,
+	).Scan(&segue)
+	if erro != nil {
+		return false, erro
+	}
+
+	return segue, nil
+}
+
+// Silenciar esconde as publicações de um usuário do feed de quem silenciou, até a expiração (se houver)
+func (repositorio Usuarios) Silenciar(usuarioID, silenciadoID uint64, expiraEm *time.Time) error {
+	statement, erro := repositorio.db.Prepare(
+		`INSERT INTO silenciados (usuario_id, silenciado_id, expira_em)
+        VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3::float8))
+        ON CONFLICT (usuario_id, silenciado_id) DO UPDATE
+        SET expira_em = EXCLUDED.expira_em`,
 	)
 	if erro != nil {
 		return erro
 	}
 	defer statement.Close()
 
-	if _, erro = statement.Exec(senha, usuarioID); erro != nil {
+	if _, erro = statement.Exec(usuarioID, silenciadoID, segundosAte(expiraEm)); erro != nil {
 		return erro
 	}
 	return nil
 }
+
+// Dessilenciar volta a mostrar as publicações de um usuário silenciado
+func (repositorio Usuarios) Dessilenciar(usuarioID, silenciadoID uint64) error {
+	statement, erro := repositorio.db.Prepare(
+		`DELETE FROM silenciados
+        WHERE usuario_id = $1 AND silenciado_id = $2`,
+	)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.Exec(usuarioID, silenciadoID); erro != nil {
+		return erro
+	}
+	return nil
+}
+
+// BuscarSilenciados traz os usuários que ainda estão silenciados por um usuário
+func (repositorio Usuarios) BuscarSilenciados(usuarioID uint64) ([]models.Silenciamento, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT u.id, u.nick, s.expira_em, s.criado_em
+        FROM silenciados s
+        INNER JOIN usuarios u ON u.id = s.silenciado_id
+        WHERE s.usuario_id = $1
+          AND (s.expira_em IS NULL OR s.expira_em > CURRENT_TIMESTAMP)
+        ORDER BY s.criado_em DESC`,
+		usuarioID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var silenciados []models.Silenciamento
+	for linhas.Next() {
+		var (
+			silenciamento models.Silenciamento
+			expiraEm      sql.NullTime
+		)
+
+		if erro = linhas.Scan(
+			&silenciamento.UsuarioID,
+			&silenciamento.Nick,
+			&expiraEm,
+			&silenciamento.CriadoEm,
+		); erro != nil {
+			return nil, erro
+		}
+
+		if expiraEm.Valid {
+			silenciamento.ExpiraEm = &expiraEm.Time
+		}
+
+		silenciados = append(silenciados, silenciamento)
+	}
+
+	return silenciados, linhas.Err()
+}
+
+// segundosAte converte uma expiração opcional em segundos a partir de agora, usados como intervalo no banco
+func segundosAte(expiraEm *time.Time) sql.NullFloat64 {
+	if expiraEm == nil {
+		return sql.NullFloat64{}
+	}
+
+	return sql.NullFloat64{Float64: time.Until(*expiraEm).Seconds(), Valid: true}
+}
+
+// AtualizarPrivacidade torna o perfil privado ou público; ao ficar público, as solicitações pendentes são aprovadas
+func (repositorio Usuarios) AtualizarPrivacidade(usuarioID uint64, privado bool) error {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	if _, erro = transacao.Exec(
+		`UPDATE usuarios
+        SET privado = $1
+        WHERE id = $2`,
+		privado, usuarioID,
+	); erro != nil {
+		return erro
+	}
+
+	if !privado {
+		linhas, erro := transacao.Query(
+			`INSERT INTO seguidores (usuario_id, seguidor_id)
+            SELECT usuario_id, solicitante_id
+            FROM solicitacoes_seguir
+            WHERE usuario_id = $1
+            ON CONFLICT (usuario_id, seguidor_id) DO NOTHING
+            RETURNING seguidor_id`,
+			usuarioID,
+		)
+		if erro != nil {
+			return erro
+		}
+
+		var aprovados []uint64
+		for linhas.Next() {
+			var seguidorID uint64
+			if erro = linhas.Scan(&seguidorID); erro != nil {
+				linhas.Close()
+				return erro
+			}
+			aprovados = append(aprovados, seguidorID)
+		}
+		linhas.Close()
+		if erro = linhas.Err(); erro != nil {
+			return erro
+		}
+
+		for _, seguidorID := range aprovados {
+			seguimento := models.Seguimento{UsuarioID: usuarioID, SeguidorID: seguidorID, Aprovado: true}
+			if erro = registrarEvento(transacao, models.EventoUsuarioSeguido, seguimento); erro != nil {
+				return erro
+			}
+		}
+
+		if _, erro = transacao.Exec(
+			`DELETE FROM solicitacoes_seguir
+            WHERE usuario_id = $1`,
+			usuarioID,
+		); erro != nil {
+			return erro
+		}
+	}
+
+	return transacao.Commit()
+}
+
+// SolicitarSeguir registra um pedido pendente para seguir um perfil privado. Quem já é seguidor não gera pedido.
+func (repositorio Usuarios) SolicitarSeguir(usuarioID, solicitanteID uint64) error {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return erro
+	}
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(
+		`INSERT INTO solicitacoes_seguir (usuario_id, solicitante_id)
+        SELECT $1, $2
+        WHERE NOT EXISTS (
+          SELECT 1 FROM seguidores
+          WHERE usuario_id = $1 AND seguidor_id = $2
+        )
+        ON CONFLICT (usuario_id, solicitante_id) DO NOTHING`,
+		usuarioID, solicitanteID,
+	)
+	if erro != nil {
+		return erro
+	}
+
+	if linhas, erro := resultado.RowsAffected(); erro != nil || linhas == 0 {
+		return erro
+	}
+
+	seguimento := models.Seguimento{UsuarioID: usuarioID, SeguidorID: solicitanteID}
+	if erro = registrarEvento(transacao, models.EventoUsuarioSeguirSolicitado, seguimento); erro != nil {
+		return erro
+	}
+
+	return transacao.Commit()
+}
+
+// BuscarSolicitacoesSeguir traz os pedidos pendentes para seguir um usuário
+func (repositorio Usuarios) BuscarSolicitacoesSeguir(usuarioID uint64) ([]models.SolicitacaoSeguir, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT u.id, u.nome, u.nick, s.criado_em
+        FROM solicitacoes_seguir s
+        INNER JOIN usuarios u ON u.id = s.solicitante_id
+        WHERE s.usuario_id = $1
+        ORDER BY s.criado_em`,
+		usuarioID,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var solicitacoes []models.SolicitacaoSeguir
+	for linhas.Next() {
+		var solicitacao models.SolicitacaoSeguir
+		if erro = linhas.Scan(
+			&solicitacao.SolicitanteID,
+			&solicitacao.Nome,
+			&solicitacao.Nick,
+			&solicitacao.CriadaEm,
+		); erro != nil {
+			return nil, erro
+		}
+
+		solicitacoes = append(solicitacoes, solicitacao)
+	}
+
+	return solicitacoes, linhas.Err()
+}
+
+// ResponderSolicitacaoSeguir remove o pedido pendente e, se aprovado, cria a relação de seguidor;
+// retorna false quando não há pedido pendente
+func (repositorio Usuarios) ResponderSolicitacaoSeguir(usuarioID, solicitanteID uint64, aprovar bool) (bool, error) {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return false, erro
+	}
+	defer transacao.Rollback()
+
+	resultado, erro := transacao.Exec(
+		`DELETE FROM solicitacoes_seguir
+        WHERE usuario_id = $1 AND solicitante_id = $2`,
+		usuarioID, solicitanteID,
+	)
+	if erro != nil {
+		return false, erro
+	}
+
+	linhas, erro := resultado.RowsAffected()
+	if erro != nil {
+		return false, erro
+	}
+	if linhas == 0 {
+		return false, nil
+	}
+
+	if aprovar {
+		resultado, erro = transacao.Exec(
+			`INSERT INTO seguidores (usuario_id, seguidor_id)
+            VALUES ($1, $2)
+            ON CONFLICT (usuario_id, seguidor_id) DO NOTHING`,
+			usuarioID, solicitanteID,
+		)
+		if erro != nil {
+			return false, erro
+		}
+
+		if linhas, erro = resultado.RowsAffected(); erro != nil {
+			return false, erro
+		}
+		if linhas > 0 {
+			seguimento := models.Seguimento{UsuarioID: usuarioID, SeguidorID: solicitanteID, Aprovado: true}
+			if erro = registrarEvento(transacao, models.EventoUsuarioSeguido, seguimento); erro != nil {
+				return false, erro
+			}
+		}
+	}
+
+	return true, transacao.Commit()
+}
+
+// PodeVerPublicacoes informa se o solicitante pode ver as publicações do autor: perfis públicos,
+// o próprio autor e seguidores aprovados de perfis privados
+func (repositorio Usuarios) PodeVerPublicacoes(autorID, solicitanteID uint64) (bool, error) {
+	var pode bool
+	erro := repositorio.db.QueryRow(
+		`SELECT $1 = $2
+            OR NOT u.privado
+            OR EXISTS (SELECT 1 FROM seguidores s WHERE s.usuario_id = u.id AND s.seguidor_id = $2)
+        FROM usuarios u
+        WHERE u.id = $1`,
+		autorID, solicitanteID,
+	).Scan(&pode)
+	if erro == sql.ErrNoRows {
+		return true, nil
+	}
+	if erro != nil {
+		return false, erro
+	}
+
+	return pode, nil
+}
+
+// BuscarSugestoes recomenda contas para o usuário seguir. Primeiro vêm as seguidas por quem ele segue, ordenadas
+// por quantos desses seguidores em comum existem; depois, as contas mais seguidas da rede. Ficam de fora o próprio
+// usuário, quem ele já segue ou pediu para seguir, contas suspensas, silenciadas e com bloqueio em qualquer sentido.
+func (repositorio Usuarios) BuscarSugestoes(usuarioID, limite uint64) ([]models.Sugestao, error) {
+	linhas, erro := repositorio.db.Query(
+		`WITH amigos_de_amigos AS (
+            SELECT s.usuario_id, COUNT(*) AS em_comum,
+                   (array_agg(intermediario.nick ORDER BY intermediario.nick))[1:2] AS nicks
+            FROM seguidores meus
+            JOIN seguidores s ON s.seguidor_id = meus.usuario_id
+            JOIN usuarios intermediario ON intermediario.id = meus.usuario_id
+            WHERE meus.seguidor_id = $1
+            GROUP BY s.usuario_id
+        ),
+        populares AS (
+            SELECT usuario_id, COUNT(*) AS seguidores
+            FROM seguidores
+            GROUP BY usuario_id
+        )
+        SELECT u.id, u.nome, u.nick, u.bio, u.criado_em,
+               COALESCE(a.em_comum, 0), COALESCE(a.nicks, '{}'), COALESCE(p.seguidores, 0)
+        FROM usuarios u
+        LEFT JOIN amigos_de_amigos a ON a.usuario_id = u.id
+        LEFT JOIN populares p ON p.usuario_id = u.id
+        WHERE u.id <> $1
+          AND NOT u.suspenso
+          AND (a.usuario_id IS NOT NULL OR p.usuario_id IS NOT NULL)
+          AND NOT EXISTS (SELECT 1 FROM seguidores s WHERE s.usuario_id = u.id AND s.seguidor_id = $1)
+          AND NOT EXISTS (SELECT 1 FROM solicitacoes_seguir ss WHERE ss.usuario_id = u.id AND ss.solicitante_id = $1)
+          AND NOT EXISTS (
+            SELECT 1 FROM bloqueios b
+            WHERE (b.usuario_id = u.id AND b.bloqueado_id = $1)
+               OR (b.usuario_id = $1 AND b.bloqueado_id = u.id)
+          )
+          AND NOT EXISTS (
+            SELECT 1 FROM silenciados m
+            WHERE m.usuario_id = $1 AND m.silenciado_id = u.id
+              AND (m.expira_em IS NULL OR m.expira_em > CURRENT_TIMESTAMP)
+          )
+        ORDER BY COALESCE(a.em_comum, 0) DESC, COALESCE(p.seguidores, 0) DESC, u.id
+        LIMIT $2`,
+		usuarioID, limite,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var sugestoes []models.Sugestao
+	for linhas.Next() {
+		var sugestao models.Sugestao
+		if erro = linhas.Scan(
+			&sugestao.ID,
+			&sugestao.Nome,
+			&sugestao.Nick,
+			&sugestao.Bio,
+			&sugestao.CriadoEm,
+			&sugestao.SeguidoresEmComum,
+			pq.Array(&sugestao.NicksEmComum),
+			&sugestao.Seguidores,
+		); erro != nil {
+			return nil, erro
+		}
+
+		sugestao.GerarMotivo()
+		sugestoes = append(sugestoes, sugestao)
+	}
+
+	return sugestoes, linhas.Err()
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// VerificacoesDeEmail representa um repositório de tokens de verificação de email
+type VerificacoesDeEmail struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeVerificacoesDeEmail cria um repositório de verificações de email
+func NovoRepositorioDeVerificacoesDeEmail(db *sql.DB) *VerificacoesDeEmail {
+	return &VerificacoesDeEmail{db}
+}
+
+// Criar invalida os tokens pendentes do usuário e salva o hash de um novo token
+func (repositorio VerificacoesDeEmail) Criar(usuarioID uint64, tokenHash string, validade time.Duration) error {
+	return criarTokenUsoUnico(repositorio.db, tabelaVerificacoesEmail, usuarioID, tokenHash, validade)
+}
+
+// Confirmar consome o token e marca o email do usuário dono dele como verificado
+func (repositorio VerificacoesDeEmail) Confirmar(tokenHash string) (uint64, error) {
+	transacao, erro := repositorio.db.Begin()
+	if erro != nil {
+		return 0, erro
+	}
+	defer transacao.Rollback()
+
+	usuarioID, erro := consumirTokenUsoUnico(transacao, tabelaVerificacoesEmail, tokenHash)
+	if erro != nil {
+		return 0, erro
+	}
+
+	if _, erro = transacao.Exec(
+		`UPDATE usuarios
+        SET email_verificado = TRUE
+        WHERE id = $1`,
+		usuarioID,
+	); erro != nil {
+		return 0, erro
+	}
+
+	if erro = transacao.Commit(); erro != nil {
+		return 0, erro
+	}
+
+	return usuarioID, nil
+}
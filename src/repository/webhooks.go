@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Webhooks representa um repositório de webhooks e de suas entregas
+type Webhooks struct {
+	db *sql.DB
+}
+
+// NovoRepositorioDeWebhooks cria um repositório de webhooks
+func NovoRepositorioDeWebhooks(db *sql.DB) *Webhooks {
+	return &Webhooks{db}
+}
+
+const colunasEntregaWebhook = `id, webhook_id, evento, payload, status, tentativas, proxima_tentativa,
+        ultimo_status_http, ultimo_erro, criado_em, entregue_em`
+
+// Criar insere um webhook no banco de dados
+func (repositorio Webhooks) Criar(webhook models.Webhook, criadoPor uint64) (uint64, error) {
+	var ID uint64
+	erro := repositorio.db.QueryRow(
+		`INSERT INTO webhooks (url, eventos, segredo, criado_por)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id`,
+		webhook.URL, pq.Array(webhook.Eventos), webhook.Segredo, criadoPor,
+	).Scan(&ID)
+	if erro != nil {
+		return 0, erro
+	}
+
+	return ID, nil
+}
+
+// Buscar traz todos os webhooks cadastrados, sem os segredos
+func (repositorio Webhooks) Buscar() ([]models.Webhook, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT id, url, eventos, ativo, criado_em
+        FROM webhooks
+        ORDER BY id`,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var webhooks []models.Webhook
+	for linhas.Next() {
+		var webhook models.Webhook
+		if erro = linhas.Scan(
+			&webhook.ID,
+			&webhook.URL,
+			pq.Array(&webhook.Eventos),
+			&webhook.Ativo,
+			&webhook.CriadoEm,
+		); erro != nil {
+			return nil, erro
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, linhas.Err()
+}
+
+// BuscarPorID traz um webhook, sem o segredo, ou um webhook vazio se ele não existir
+func (repositorio Webhooks) BuscarPorID(webhookID uint64) (models.Webhook, error) {
+	var webhook models.Webhook
+	erro := repositorio.db.QueryRow(
+		`SELECT id, url, eventos, ativo, criado_em
+        FROM webhooks
+        WHERE id = $1`,
+		webhookID,
+	).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		pq.Array(&webhook.Eventos),
+		&webhook.Ativo,
+		&webhook.CriadoEm,
+	)
+	if erro == sql.ErrNoRows {
+		return models.Webhook{}, nil
+	}
+	if erro != nil {
+		return models.Webhook{}, erro
+	}
+
+	return webhook, nil
+}
+
+// Deletar exclui um webhook junto com o seu histórico de entregas
+func (repositorio Webhooks) Deletar(webhookID uint64) error {
+	statement, erro := repositorio.db.Prepare(`DELETE FROM webhooks WHERE id = $1`)
+	if erro != nil {
+		return erro
+	}
+	defer statement.Close()
+
+	if _, erro = statement.Exec(webhookID); erro != nil {
+		return erro
+	}
+
+	return nil
+}
+
+// Enfileirar cria uma entrega pendente do evento para cada webhook ativo que o assina
+func (repositorio Webhooks) Enfileirar(evento, payload string) (int64, error) {
+	resultado, erro := repositorio.db.Exec(
+		`INSERT INTO entregas_webhook (webhook_id, evento, payload)
+        SELECT id, $1, $2
+        FROM webhooks
+        WHERE ativo AND $1 = ANY(eventos)`,
+		evento, payload,
+	)
+	if erro != nil {
+		return 0, erro
+	}
+
+	return resultado.RowsAffected()
+}
+
+// BuscarEntregas traz as entregas mais recentes de um webhook
+func (repositorio Webhooks) BuscarEntregas(webhookID, limite uint64) ([]models.EntregaWebhook, error) {
+	linhas, erro := repositorio.db.Query(
+		`SELECT `+colunasEntregaWebhook+`
+        FROM entregas_webhook
+        WHERE webhook_id = $1
+        ORDER BY id DESC
+        LIMIT $2`,
+		webhookID, limite,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var entregas []models.EntregaWebhook
+	for linhas.Next() {
+		entrega, erro := escanearEntregaWebhook(linhas)
+		if erro != nil {
+			return nil, erro
+		}
+
+		entregas = append(entregas, entrega)
+	}
+
+	return entregas, linhas.Err()
+}
+
+// Reenviar cria uma nova entrega com o mesmo evento e payload de uma entrega do webhook,
+// retornando zero se a entrega não existir
+func (repositorio Webhooks) Reenviar(webhookID, entregaID uint64) (uint64, error) {
+	var ID uint64
+	erro := repositorio.db.QueryRow(
+		`INSERT INTO entregas_webhook (webhook_id, evento, payload)
+        SELECT webhook_id, evento, payload
+        FROM entregas_webhook
+        WHERE id = $1 AND webhook_id = $2
+        RETURNING id`,
+		entregaID, webhookID,
+	).Scan(&ID)
+	if erro == sql.ErrNoRows {
+		return 0, nil
+	}
+	if erro != nil {
+		return 0, erro
+	}
+
+	return ID, nil
+}
+
+// ReservarPendentes separa as entregas que já podem ser tentadas, contando a tentativa e adiando a próxima
+// pelo tempo de reserva para que outra instância da API não as envie ao mesmo tempo
+func (repositorio Webhooks) ReservarPendentes(limite uint64, reserva time.Duration) ([]models.EnvioWebhook, error) {
+	linhas, erro := repositorio.db.Query(
+		`UPDATE entregas_webhook e
+        SET tentativas = e.tentativas + 1,
+            proxima_tentativa = CURRENT_TIMESTAMP + make_interval(secs => $2::float8)
+        FROM webhooks w
+        WHERE w.id = e.webhook_id
+          AND e.id IN (
+            SELECT id FROM entregas_webhook
+            WHERE status = $3 AND proxima_tentativa <= CURRENT_TIMESTAMP
+            ORDER BY proxima_tentativa
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+          )
+        RETURNING e.id, e.webhook_id, e.evento, e.payload, e.tentativas, w.url, w.segredo`,
+		limite, reserva.Seconds(), models.StatusEntregaPendente,
+	)
+	if erro != nil {
+		return nil, erro
+	}
+	defer linhas.Close()
+
+	var envios []models.EnvioWebhook
+	for linhas.Next() {
+		var envio models.EnvioWebhook
+		if erro = linhas.Scan(
+			&envio.Entrega.ID,
+			&envio.Entrega.WebhookID,
+			&envio.Entrega.Evento,
+			&envio.Entrega.Payload,
+			&envio.Entrega.Tentativas,
+			&envio.URL,
+			&envio.Segredo,
+		); erro != nil {
+			return nil, erro
+		}
+
+		envios = append(envios, envio)
+	}
+
+	return envios, linhas.Err()
+}
+
+// RegistrarSucesso marca a entrega como entregue
+func (repositorio Webhooks) RegistrarSucesso(entregaID uint64, statusHTTP int) error {
+	_, erro := repositorio.db.Exec(
+		`UPDATE entregas_webhook
+        SET status = $2, ultimo_status_http = $3, ultimo_erro = NULL, entregue_em = CURRENT_TIMESTAMP
+        WHERE id = $1`,
+		entregaID, models.StatusEntregaEntregue, statusHTTP,
+	)
+	return erro
+}
+
+// RegistrarFalha guarda o erro da tentativa e agenda a próxima, ou desiste da entrega quando não houver nova tentativa
+func (repositorio Webhooks) RegistrarFalha(entregaID uint64, statusHTTP int, mensagem string, proximaTentativa time.Duration, desistir bool) error {
+	var status sql.NullInt64
+	if statusHTTP != 0 {
+		status = sql.NullInt64{Int64: int64(statusHTTP), Valid: true}
+	}
+
+	situacao := models.StatusEntregaPendente
+	if desistir {
+		situacao = models.StatusEntregaFalhou
+	}
+
+	_, erro := repositorio.db.Exec(
+		`UPDATE entregas_webhook
+        SET status = $2, ultimo_status_http = $3, ultimo_erro = $4,
+            proxima_tentativa = CURRENT_TIMESTAMP + make_interval(secs => $5::float8)
+        WHERE id = $1`,
+		entregaID, situacao, status, mensagem, proximaTentativa.Seconds(),
+	)
+	return erro
+}
+
+func escanearEntregaWebhook(linhas *sql.Rows) (models.EntregaWebhook, error) {
+	var (
+		entrega          models.EntregaWebhook
+		proximaTentativa time.Time
+		statusHTTP       sql.NullInt64
+		ultimoErro       sql.NullString
+		entregueEm       sql.NullTime
+	)
+
+	if erro := linhas.Scan(
+		&entrega.ID,
+		&entrega.WebhookID,
+		&entrega.Evento,
+		&entrega.Payload,
+		&entrega.Status,
+		&entrega.Tentativas,
+		&proximaTentativa,
+		&statusHTTP,
+		&ultimoErro,
+		&entrega.CriadaEm,
+		&entregueEm,
+	); erro != nil {
+		return models.EntregaWebhook{}, erro
+	}
+
+	// A próxima tentativa só interessa enquanto a entrega ainda está pendente
+	if entrega.Status == models.StatusEntregaPendente {
+		entrega.ProximaTentativa = &proximaTentativa
+	}
+	entrega.UltimoStatusHTTP = uint64(statusHTTP.Int64)
+	entrega.UltimoErro = ultimoErro.String
+	if entregueEm.Valid {
+		entrega.EntregueEm = &entregueEm.Time
+	}
+
+	return entrega, nil
+}
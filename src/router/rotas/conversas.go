@@ -0,0 +1,39 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasConversas = []Rota{
+	{
+		URI:                "/conversas",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CriarConversa,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/conversas",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarConversas,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/conversas/{conversaId}/mensagens",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.EnviarMensagem,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/conversas/{conversaId}/mensagens",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarMensagens,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/conversas/{conversaId}/lida",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.MarcarConversaComoLida,
+		RequerAltenticacao: true,
+	},
+}
@@ -0,0 +1,52 @@
+package rotas
+
+import (
+	"api/src/autenticacao"
+	"api/src/controllers"
+	"net/http"
+)
+
+var papeisModeracao = []string{autenticacao.PapelModerador, autenticacao.PapelAdmin}
+
+var rotasDenuncias = []Rota{
+	{
+		URI:                "/publicacoes/{publicacaoId}/denunciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DenunciarPublicacao,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/denunciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DenunciarUsuario,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/denuncias",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarDenuncias,
+		RequerAltenticacao: true,
+		Papeis:             papeisModeracao,
+	},
+	{
+		URI:                "/denuncias/{denunciaId}/assumir",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.AssumirDenuncia,
+		RequerAltenticacao: true,
+		Papeis:             papeisModeracao,
+	},
+	{
+		URI:                "/denuncias/{denunciaId}/resolver",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ResolverDenuncia,
+		RequerAltenticacao: true,
+		Papeis:             papeisModeracao,
+	},
+	{
+		URI:                "/denuncias/{denunciaId}/historico",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarHistoricoDenuncia,
+		RequerAltenticacao: true,
+		Papeis:             papeisModeracao,
+	},
+}
@@ -0,0 +1,21 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasEmail = []Rota{
+	{
+		URI:                "/email/verificar",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.VerificarEmail,
+		RequerAltenticacao: false,
+	},
+	{
+		URI:                "/email/reenviar-verificacao",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ReenviarVerificacaoDeEmail,
+		RequerAltenticacao: true,
+	},
+}
@@ -0,0 +1,13 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotaEventos = Rota{
+	URI:                "/eventos",
+	Metodo:             http.MethodGet,
+	Funcao:             controllers.Eventos,
+	RequerAltenticacao: true,
+}
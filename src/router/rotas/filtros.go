@@ -0,0 +1,27 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasFiltros = []Rota{
+	{
+		URI:                "/filtros",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CriarFiltroPalavra,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/filtros",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarFiltrosPalavras,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/filtros/{filtroId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DeletarFiltroPalavra,
+		RequerAltenticacao: true,
+	},
+}
@@ -0,0 +1,21 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasHashtags = []Rota{
+	{
+		URI:                "/hashtags/em-alta",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarHashtagsEmAlta,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/hashtags/{hashtag}/publicacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarPublicacoesPorHashtag,
+		RequerAltenticacao: true,
+	},
+}
@@ -11,3 +11,10 @@ var rotaLogin = Rota{
 	Funcao:             controllers.Login,
 	RequerAltenticacao: false,
 }
+
+var rotaLoginDoisFatores = Rota{
+	URI:                "/login/2fa",
+	Metodo:             http.MethodPost,
+	Funcao:             controllers.LoginDoisFatores,
+	RequerAltenticacao: false,
+}
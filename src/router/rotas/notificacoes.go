@@ -0,0 +1,27 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasNotificacoes = []Rota{
+	{
+		URI:                "/notificacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarNotificacoes,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/notificacoes/lidas",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.MarcarTodasNotificacoesComoLidas,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/notificacoes/{notificacaoId}/lida",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.MarcarNotificacaoComoLida,
+		RequerAltenticacao: true,
+	},
+}
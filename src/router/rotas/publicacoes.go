@@ -18,6 +18,12 @@ var rotasPublicacoes = []Rota{
 		Funcao:             controllers.BuscarPublicacoes,
 		RequerAltenticacao: true,
 	},
+	{
+		URI:                "/publicacoes/busca",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.PesquisarPublicacoes,
+		RequerAltenticacao: true,
+	},
 	{
 		URI:                "/publicacoes/{publicacaoId}",
 		Metodo:             http.MethodGet,
@@ -36,6 +42,12 @@ var rotasPublicacoes = []Rota{
 		Funcao:             controllers.DeletarPublicacao,
 		RequerAltenticacao: true,
 	},
+	{
+		URI:                "/mencoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarMencoes,
+		RequerAltenticacao: true,
+	},
 	{
 		URI:                "/usuarios/{usuarioId}/publicacoes",
 		Metodo:             http.MethodGet,
@@ -54,4 +66,22 @@ var rotasPublicacoes = []Rota{
 		Funcao:             controllers.DescurtirPublicacao,
 		RequerAltenticacao: true,
 	},
+	{
+		URI:                "/publicacoes/{publicacaoId}/citacoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarCitacoes,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/publicacoes/{publicacaoId}/republicar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.RepublicarPublicacao,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/publicacoes/{publicacaoId}/desfazer-republicacao",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DesfazerRepublicacao,
+		RequerAltenticacao: true,
+	},
 }
@@ -13,17 +13,32 @@ type Rota struct {
 	Metodo             string
 	Funcao             func(http.ResponseWriter, *http.Request)
 	RequerAltenticacao bool
+	// Papeis lista os papéis autorizados a usar a rota; vazio permite qualquer usuário autenticado
+	Papeis []string
 }
 
 // Configurar coloca todas as rotas dentro do router
 func Configurar(r *mux.Router) *mux.Router {
 	rotas := rotasUsuarios
-	rotas = append(rotas, rotaLogin)
+	rotas = append(rotas, rotaLogin, rotaLoginDoisFatores)
 	rotas = append(rotas, rotasPublicacoes...)
+	rotas = append(rotas, rotasSenha...)
+	rotas = append(rotas, rotasEmail...)
+	rotas = append(rotas, rotasDenuncias...)
+	rotas = append(rotas, rotasFiltros...)
+	rotas = append(rotas, rotasConversas...)
+	rotas = append(rotas, rotasNotificacoes...)
+	rotas = append(rotas, rotaEventos)
+	rotas = append(rotas, rotasWebhooks...)
+	rotas = append(rotas, rotasHashtags...)
 
 	for _, rota := range rotas {
 
-		if rota.RequerAltenticacao {
+		if len(rota.Papeis) > 0 {
+			r.HandleFunc(rota.URI,
+				middlewares.Logger(middlewares.Autenticar(middlewares.Autorizar(rota.Papeis, rota.Funcao))),
+			).Methods(rota.Metodo)
+		} else if rota.RequerAltenticacao {
 			r.HandleFunc(rota.URI,
 				middlewares.Logger(middlewares.Autenticar(rota.Funcao)),
 				).Methods(rota.Metodo)
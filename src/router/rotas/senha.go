@@ -0,0 +1,21 @@
+package rotas
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var rotasSenha = []Rota{
+	{
+		URI:                "/senha/esqueci",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.EsquecerSenha,
+		RequerAltenticacao: false,
+	},
+	{
+		URI:                "/senha/redefinir",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.RedefinirSenha,
+		RequerAltenticacao: false,
+	},
+}
@@ -1,6 +1,7 @@
 package rotas
 
 import (
+	"api/src/autenticacao"
 	"api/src/controllers"
 	"net/http"
 )
@@ -18,6 +19,12 @@ var rotasUsuarios = []Rota{
 		Funcao:             controllers.BurscarUsuarios,
 		RequerAltenticacao: true,
 	},
+	{
+		URI:                "/usuarios/sugestoes",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarSugestoes,
+		RequerAltenticacao: true,
+	},
 	{
 		URI:                "/usuarios/{usuarioId}",
 		Metodo:             http.MethodGet,
@@ -48,6 +55,84 @@ var rotasUsuarios = []Rota{
 		Funcao: controllers.PararDeSeguirUsuario,
 		RequerAltenticacao: true,
 	},
+	{
+		URI:                "/usuarios/{usuarioId}/bloquear",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.BloquearUsuario,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/desbloquear",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DesbloquearUsuario,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/silenciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.SilenciarUsuario,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/dessilenciar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.DessilenciarUsuario,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/silenciados",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarSilenciados,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/avatar",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarAvatar,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/avatar",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.RemoverAvatar,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/banner",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarBanner,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/banner",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.RemoverBanner,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/privacidade",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarPrivacidade,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/solicitacoes-seguir",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarSolicitacoesSeguir,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/solicitacoes-seguir/{solicitanteId}/aprovar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.AprovarSolicitacaoSeguir,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/solicitacoes-seguir/{solicitanteId}/rejeitar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.RejeitarSolicitacaoSeguir,
+		RequerAltenticacao: true,
+	},
 	{
 		URI: "/usuarios/{usuarioId}/seguidores",
 		Metodo: http.MethodGet,
@@ -66,4 +151,29 @@ var rotasUsuarios = []Rota{
 		Funcao: controllers.AtualizarSenha,
 		RequerAltenticacao: true,
 	},
+	{
+		URI:                "/usuarios/{usuarioId}/2fa",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CadastrarDoisFatores,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/2fa/confirmar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ConfirmarDoisFatores,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/2fa",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DesativarDoisFatores,
+		RequerAltenticacao: true,
+	},
+	{
+		URI:                "/usuarios/{usuarioId}/papel",
+		Metodo:             http.MethodPut,
+		Funcao:             controllers.AtualizarPapelUsuario,
+		RequerAltenticacao: true,
+		Papeis:             []string{autenticacao.PapelAdmin},
+	},
 }
@@ -0,0 +1,47 @@
+package rotas
+
+import (
+	"api/src/autenticacao"
+	"api/src/controllers"
+	"net/http"
+)
+
+var papeisWebhooks = []string{autenticacao.PapelAdmin}
+
+var rotasWebhooks = []Rota{
+	{
+		URI:                "/webhooks",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.CriarWebhook,
+		RequerAltenticacao: true,
+		Papeis:             papeisWebhooks,
+	},
+	{
+		URI:                "/webhooks",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarWebhooks,
+		RequerAltenticacao: true,
+		Papeis:             papeisWebhooks,
+	},
+	{
+		URI:                "/webhooks/{webhookId}",
+		Metodo:             http.MethodDelete,
+		Funcao:             controllers.DeletarWebhook,
+		RequerAltenticacao: true,
+		Papeis:             papeisWebhooks,
+	},
+	{
+		URI:                "/webhooks/{webhookId}/entregas",
+		Metodo:             http.MethodGet,
+		Funcao:             controllers.BuscarEntregasWebhook,
+		RequerAltenticacao: true,
+		Papeis:             papeisWebhooks,
+	},
+	{
+		URI:                "/webhooks/{webhookId}/entregas/{entregaId}/reenviar",
+		Metodo:             http.MethodPost,
+		Funcao:             controllers.ReenviarEntregaWebhook,
+		RequerAltenticacao: true,
+		Papeis:             papeisWebhooks,
+	},
+}
@@ -1,7 +1,10 @@
 package router
 
 import (
+	"api/src/armazenamento"
+	"api/src/config"
 	"api/src/router/rotas"
+	"net/http"
 
 	"github.com/gorilla/mux"
 )
@@ -9,5 +12,13 @@ import (
 // Gerar vai retornar um router com as rotas configuradas
 func Gerar() *mux.Router {
 	r := mux.NewRouter()
+
+	// Com o armazenamento local, a própria API serve as imagens enviadas
+	if config.Armazenamento == "local" {
+		r.PathPrefix("/midias/").Handler(
+			http.StripPrefix("/midias/", armazenamento.ServidorLocal(config.ArmazenamentoDiretorio)),
+		).Methods(http.MethodGet)
+	}
+
 	return rotas.Configurar(r)
 }
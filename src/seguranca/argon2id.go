@@ -0,0 +1,130 @@
+package seguranca
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	tamanhoSaltArgon2  = 16
+	tamanhoChaveArgon2 = 32
+)
+
+// Argon2id gera e verifica hashes argon2id no formato PHC ($argon2id$v=19$m=...,t=...,p=...$salt$hash)
+type Argon2id struct {
+	Memoria     uint32
+	Iteracoes   uint32
+	Paralelismo uint8
+}
+
+type parametrosArgon2 struct {
+	memoria     uint32
+	iteracoes   uint32
+	paralelismo uint8
+	salt        []byte
+	chave       []byte
+}
+
+// Hash gera o hash argon2id da senha com um salt aleatório
+func (hasher Argon2id) Hash(senha string) (string, error) {
+	hasher = hasher.comPadroes()
+
+	salt := make([]byte, tamanhoSaltArgon2)
+	if _, erro := rand.Read(salt); erro != nil {
+		return "", erro
+	}
+
+	chave := argon2.IDKey([]byte(senha), salt, hasher.Iteracoes, hasher.Memoria, hasher.Paralelismo, tamanhoChaveArgon2)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, hasher.Memoria, hasher.Iteracoes, hasher.Paralelismo,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(chave),
+	), nil
+}
+
+// Verificar compara a senha com um hash argon2id usando os parâmetros salvos no próprio hash
+func (hasher Argon2id) Verificar(senhaComHash, senha string) error {
+	parametros, erro := decodificarArgon2(senhaComHash)
+	if erro != nil {
+		return erro
+	}
+
+	chave := argon2.IDKey([]byte(senha), parametros.salt, parametros.iteracoes, parametros.memoria, parametros.paralelismo, uint32(len(parametros.chave)))
+	if subtle.ConstantTimeCompare(chave, parametros.chave) != 1 {
+		return ErrSenhaIncorreta
+	}
+
+	return nil
+}
+
+// Reconhece informa se o hash está no formato argon2id
+func (hasher Argon2id) Reconhece(senhaComHash string) bool {
+	return strings.HasPrefix(senhaComHash, "$argon2id$")
+}
+
+// Desatualizado informa se o hash foi gerado com parâmetros diferentes dos configurados
+func (hasher Argon2id) Desatualizado(senhaComHash string) bool {
+	parametros, erro := decodificarArgon2(senhaComHash)
+	if erro != nil {
+		return true
+	}
+
+	hasher = hasher.comPadroes()
+	return parametros.memoria != hasher.Memoria ||
+		parametros.iteracoes != hasher.Iteracoes ||
+		parametros.paralelismo != hasher.Paralelismo ||
+		len(parametros.chave) != tamanhoChaveArgon2
+}
+
+// comPadroes preenche os parâmetros não configurados com os valores recomendados
+func (hasher Argon2id) comPadroes() Argon2id {
+	if hasher.Memoria == 0 {
+		hasher.Memoria = 64 * 1024
+	}
+	if hasher.Iteracoes == 0 {
+		hasher.Iteracoes = 3
+	}
+	if hasher.Paralelismo == 0 {
+		hasher.Paralelismo = 2
+	}
+
+	return hasher
+}
+
+func decodificarArgon2(senhaComHash string) (parametrosArgon2, error) {
+	partes := strings.Split(senhaComHash, "$")
+	if len(partes) != 6 || partes[1] != "argon2id" {
+		return parametrosArgon2{}, errors.New("hash argon2id em formato inválido")
+	}
+
+	var versao int
+	if _, erro := fmt.Sscanf(partes[2], "v=%d", &versao); erro != nil {
+		return parametrosArgon2{}, erro
+	}
+	if versao != argon2.Version {
+		return parametrosArgon2{}, errors.New("versão do argon2id não suportada")
+	}
+
+	var parametros parametrosArgon2
+	if _, erro := fmt.Sscanf(partes[3], "m=%d,t=%d,p=%d", &parametros.memoria, &parametros.iteracoes, &parametros.paralelismo); erro != nil {
+		return parametrosArgon2{}, erro
+	}
+
+	var erro error
+	if parametros.salt, erro = base64.RawStdEncoding.DecodeString(partes[4]); erro != nil {
+		return parametrosArgon2{}, erro
+	}
+	if parametros.chave, erro = base64.RawStdEncoding.DecodeString(partes[5]); erro != nil {
+		return parametrosArgon2{}, erro
+	}
+
+	return parametros, nil
+}
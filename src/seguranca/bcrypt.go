@@ -0,0 +1,61 @@
+package seguranca
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt gera e verifica hashes bcrypt
+type Bcrypt struct {
+	Custo int
+}
+
+// Hash gera o hash bcrypt da senha
+func (hasher Bcrypt) Hash(senha string) (string, error) {
+	custo := hasher.Custo
+	if custo == 0 {
+		custo = bcrypt.DefaultCost
+	}
+
+	senhaComHash, erro := bcrypt.GenerateFromPassword([]byte(senha), custo)
+	if erro != nil {
+		return "", erro
+	}
+
+	return string(senhaComHash), nil
+}
+
+// Verificar compara a senha com um hash bcrypt
+func (hasher Bcrypt) Verificar(senhaComHash, senha string) error {
+	if erro := bcrypt.CompareHashAndPassword([]byte(senhaComHash), []byte(senha)); erro != nil {
+		if erro == bcrypt.ErrMismatchedHashAndPassword {
+			return ErrSenhaIncorreta
+		}
+		return erro
+	}
+
+	return nil
+}
+
+// Reconhece informa se o hash está no formato bcrypt ($2a$, $2b$ ou $2y$)
+func (hasher Bcrypt) Reconhece(senhaComHash string) bool {
+	return strings.HasPrefix(senhaComHash, "$2a$") ||
+		strings.HasPrefix(senhaComHash, "$2b$") ||
+		strings.HasPrefix(senhaComHash, "$2y$")
+}
+
+// Desatualizado informa se o hash foi gerado com um custo diferente do configurado
+func (hasher Bcrypt) Desatualizado(senhaComHash string) bool {
+	custo, erro := bcrypt.Cost([]byte(senhaComHash))
+	if erro != nil {
+		return true
+	}
+
+	custoAtual := hasher.Custo
+	if custoAtual == 0 {
+		custoAtual = bcrypt.DefaultCost
+	}
+
+	return custo != custoAtual
+}
@@ -1,13 +1,70 @@
 package seguranca
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"api/src/config"
+	"errors"
+)
+
+// ErrSenhaIncorreta indica que a senha informada não corresponde ao hash salvo
+var ErrSenhaIncorreta = errors.New("senha incorreta")
+
+// Hasher representa um algoritmo de hash de senhas
+type Hasher interface {
+	// Hash gera o hash da senha no formato que será salvo no banco de dados
+	Hash(senha string) (string, error)
+	// Verificar compara a senha com um hash gerado por este algoritmo
+	Verificar(senhaComHash, senha string) error
+	// Reconhece informa se o hash foi gerado por este algoritmo
+	Reconhece(senhaComHash string) bool
+	// Desatualizado informa se o hash usa parâmetros diferentes dos configurados atualmente
+	Desatualizado(senhaComHash string) bool
+}
+
+// hasherPadrao retorna o algoritmo configurado para gerar novos hashes
+func hasherPadrao() Hasher {
+	if config.AlgoritmoHash == "bcrypt" {
+		return Bcrypt{Custo: config.BcryptCusto}
+	}
+
+	return Argon2id{
+		Memoria:     config.Argon2Memoria,
+		Iteracoes:   config.Argon2Iteracoes,
+		Paralelismo: config.Argon2Paralelismo,
+	}
+}
+
+// hashers retorna todos os algoritmos aceitos na verificação de senhas
+func hashers() []Hasher {
+	return []Hasher{hasherPadrao(), Argon2id{}, Bcrypt{}}
+}
 
 // Hash recebe uma string e coloca um hash nela
 func Hash(senha string) ([]byte, error) {
-	return bcrypt.GenerateFromPassword([]byte(senha), bcrypt.DefaultCost)
+	senhaComHash, erro := hasherPadrao().Hash(senha)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return []byte(senhaComHash), nil
 }
 
 // VerificarSenha compara uma senha e um hash e retorna se elas são iguais
-func VerificarSenha(senhaComHash, senhaString  string) error {
-	return bcrypt.CompareHashAndPassword([]byte(senhaComHash), []byte(senhaString))
-}
\ No newline at end of file
+func VerificarSenha(senhaComHash, senhaString string) error {
+	for _, hasher := range hashers() {
+		if hasher.Reconhece(senhaComHash) {
+			return hasher.Verificar(senhaComHash, senhaString)
+		}
+	}
+
+	return ErrSenhaIncorreta
+}
+
+// PrecisaRehash informa se o hash salvo deve ser refeito com o algoritmo e os parâmetros atuais
+func PrecisaRehash(senhaComHash string) bool {
+	padrao := hasherPadrao()
+	if !padrao.Reconhece(senhaComHash) {
+		return true
+	}
+
+	return padrao.Desatualizado(senhaComHash)
+}
@@ -0,0 +1,24 @@
+package seguranca
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GerarToken cria um token aleatório seguro para ser enviado ao usuário
+func GerarToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, erro := rand.Read(bytes); erro != nil {
+		return "", erro
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashToken retorna o hash SHA-256 do token, que é o valor guardado no banco de dados
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,183 @@
+package seguranca
+
+import (
+	"api/src/config"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	periodoTOTP = 30
+	digitosTOTP = 6
+
+	// prefixoSegredoCifrado marca os segredos guardados cifrados, para separá-los dos guardados antes da cifragem
+	prefixoSegredoCifrado = "v1:"
+)
+
+var errSegredoCifradoInvalido = errors.New("segredo TOTP cifrado inválido")
+
+var codificacaoBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GerarSegredoTOTP cria um segredo aleatório em base32 para ser usado em um app autenticador
+func GerarSegredoTOTP() (string, error) {
+	bytes := make([]byte, 20)
+	if _, erro := rand.Read(bytes); erro != nil {
+		return "", erro
+	}
+
+	return codificacaoBase32.EncodeToString(bytes), nil
+}
+
+// URITOTP monta a URI otpauth:// que os apps autenticadores usam para cadastrar a conta
+func URITOTP(segredo, conta, emissor string) string {
+	parametros := url.Values{}
+	parametros.Set("secret", segredo)
+	parametros.Set("issuer", emissor)
+	parametros.Set("algorithm", "SHA1")
+	parametros.Set("digits", fmt.Sprint(digitosTOTP))
+	parametros.Set("period", fmt.Sprint(periodoTOTP))
+
+	rotulo := url.PathEscape(emissor + ":" + conta)
+	return fmt.Sprintf("otpauth://totp/%s?%s", rotulo, parametros.Encode())
+}
+
+// ValidarCodigoTOTP verifica o código informado aceitando um passo de diferença no relógio, e retorna o passo que
+// correspondeu ao código. Códigos de passos até o ultimoPasso já aceito são recusados, para que um código não seja
+// reutilizado; quem chama ainda deve gravar o novo passo de forma atômica (ver DoisFatores.RegistrarPasso).
+func ValidarCodigoTOTP(segredo, codigo string, ultimoPasso int64) (int64, bool) {
+	chave, erro := codificacaoBase32.DecodeString(strings.ToUpper(segredo))
+	if erro != nil {
+		return 0, false
+	}
+
+	return validarCodigoTOTP(chave, codigo, time.Now(), ultimoPasso)
+}
+
+func validarCodigoTOTP(chave []byte, codigo string, agora time.Time, ultimoPasso int64) (int64, bool) {
+	codigo = strings.TrimSpace(codigo)
+	passoAtual := agora.Unix() / periodoTOTP
+
+	for _, desvio := range []int64{0, -1, 1} {
+		passo := passoAtual + desvio
+		if passo <= ultimoPasso {
+			continue
+		}
+
+		esperado := gerarCodigoTOTP(chave, passo)
+		if subtle.ConstantTimeCompare([]byte(esperado), []byte(codigo)) == 1 {
+			return passo, true
+		}
+	}
+
+	return 0, false
+}
+
+// CifrarSegredoTOTP cifra o segredo com AES-256-GCM para guardá-lo no banco. Ao contrário das senhas, o segredo não
+// pode ser guardado como hash, porque o servidor precisa dele para calcular os códigos. O ID do usuário entra como
+// dado autenticado, então um segredo copiado para a linha de outro usuário não é decifrado.
+func CifrarSegredoTOTP(usuarioID uint64, segredo string) (string, error) {
+	return cifrarSegredo(config.ChaveTOTP, usuarioID, segredo)
+}
+
+// DecifrarSegredoTOTP desfaz CifrarSegredoTOTP. Segredos guardados antes da cifragem, sem o prefixo, voltam como estão.
+func DecifrarSegredoTOTP(usuarioID uint64, guardado string) (string, error) {
+	return decifrarSegredo(config.ChaveTOTP, usuarioID, guardado)
+}
+
+func cifrarSegredo(chave []byte, usuarioID uint64, segredo string) (string, error) {
+	aead, erro := cifradorGCM(chave)
+	if erro != nil {
+		return "", erro
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, erro = rand.Read(nonce); erro != nil {
+		return "", erro
+	}
+
+	cifrado := aead.Seal(nonce, nonce, []byte(segredo), dadosAutenticados(usuarioID))
+	return prefixoSegredoCifrado + base64.RawStdEncoding.EncodeToString(cifrado), nil
+}
+
+func decifrarSegredo(chave []byte, usuarioID uint64, guardado string) (string, error) {
+	if !strings.HasPrefix(guardado, prefixoSegredoCifrado) {
+		return guardado, nil
+	}
+
+	cifrado, erro := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(guardado, prefixoSegredoCifrado))
+	if erro != nil {
+		return "", errSegredoCifradoInvalido
+	}
+
+	aead, erro := cifradorGCM(chave)
+	if erro != nil {
+		return "", erro
+	}
+	if len(cifrado) < aead.NonceSize() {
+		return "", errSegredoCifradoInvalido
+	}
+
+	nonce, cifrado := cifrado[:aead.NonceSize()], cifrado[aead.NonceSize():]
+	segredo, erro := aead.Open(nil, nonce, cifrado, dadosAutenticados(usuarioID))
+	if erro != nil {
+		return "", errSegredoCifradoInvalido
+	}
+
+	return string(segredo), nil
+}
+
+func cifradorGCM(chave []byte) (cipher.AEAD, error) {
+	bloco, erro := aes.NewCipher(chave)
+	if erro != nil {
+		return nil, erro
+	}
+
+	return cipher.NewGCM(bloco)
+}
+
+func dadosAutenticados(usuarioID uint64) []byte {
+	return []byte("dois_fatores:" + strconv.FormatUint(usuarioID, 10))
+}
+
+func gerarCodigoTOTP(chave []byte, passo int64) string {
+	mensagem := make([]byte, 8)
+	binary.BigEndian.PutUint64(mensagem, uint64(passo))
+
+	mac := hmac.New(sha1.New, chave)
+	mac.Write(mensagem)
+	soma := mac.Sum(nil)
+
+	deslocamento := soma[len(soma)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(soma[deslocamento:deslocamento+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digitosTOTP, valor%1000000)
+}
+
+// GerarCodigosDeRecuperacao cria códigos de uso único para quando o usuário perder o app autenticador
+func GerarCodigosDeRecuperacao(quantidade int) ([]string, error) {
+	codigos := make([]string, quantidade)
+	for i := range codigos {
+		bytes := make([]byte, 6)
+		if _, erro := rand.Read(bytes); erro != nil {
+			return nil, erro
+		}
+
+		codigo := strings.ToLower(codificacaoBase32.EncodeToString(bytes))
+		codigos[i] = codigo[:5] + "-" + codigo[5:]
+	}
+
+	return codigos, nil
+}
@@ -0,0 +1,128 @@
+package seguranca
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// chaveRFC6238 é a chave SHA-1 dos vetores de teste do Apêndice B da RFC 6238
+var chaveRFC6238 = []byte("12345678901234567890")
+
+func TestGerarCodigoTOTP(t *testing.T) {
+	// A RFC traz códigos de 8 dígitos; os de 6 dígitos são os 6 últimos
+	vetores := []struct {
+		segundos int64
+		codigo   string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+
+	for _, vetor := range vetores {
+		if codigo := gerarCodigoTOTP(chaveRFC6238, vetor.segundos/periodoTOTP); codigo != vetor.codigo {
+			t.Errorf("gerarCodigoTOTP(T=%d) = %s, esperado %s", vetor.segundos, codigo, vetor.codigo)
+		}
+	}
+}
+
+func TestValidarCodigoTOTP(t *testing.T) {
+	agora := time.Unix(1111111111, 0)
+	passoAtual := agora.Unix() / periodoTOTP
+	codigoDoPasso := func(desvio int64) string {
+		return gerarCodigoTOTP(chaveRFC6238, passoAtual+desvio)
+	}
+
+	casos := []struct {
+		nome        string
+		codigo      string
+		ultimoPasso int64
+		passo       int64
+		valido      bool
+	}{
+		{"passo atual", codigoDoPasso(0), 0, passoAtual, true},
+		{"passo anterior", codigoDoPasso(-1), 0, passoAtual - 1, true},
+		{"próximo passo", codigoDoPasso(1), 0, passoAtual + 1, true},
+		{"dois passos atrás", codigoDoPasso(-2), 0, 0, false},
+		{"dois passos à frente", codigoDoPasso(2), 0, 0, false},
+		{"com espaços", " " + codigoDoPasso(0) + " ", 0, passoAtual, true},
+		{"código errado", "000000", 0, 0, false},
+		{"vazio", "", 0, 0, false},
+		{"reutilizado", codigoDoPasso(0), passoAtual, 0, false},
+		{"anterior ao último aceito", codigoDoPasso(-1), passoAtual, 0, false},
+		{"posterior ao último aceito", codigoDoPasso(1), passoAtual, passoAtual + 1, true},
+		{"passo anterior depois de aceito", codigoDoPasso(-1), passoAtual - 1, 0, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			passo, valido := validarCodigoTOTP(chaveRFC6238, caso.codigo, agora, caso.ultimoPasso)
+			if valido != caso.valido || passo != caso.passo {
+				t.Errorf("validarCodigoTOTP() = (%d, %v), esperado (%d, %v)", passo, valido, caso.passo, caso.valido)
+			}
+		})
+	}
+}
+
+func TestCifrarSegredoTOTP(t *testing.T) {
+	chave := []byte(strings.Repeat("k", 32))
+	segredo, erro := GerarSegredoTOTP()
+	if erro != nil {
+		t.Fatal(erro)
+	}
+
+	cifrado, erro := cifrarSegredo(chave, 7, segredo)
+	if erro != nil {
+		t.Fatal(erro)
+	}
+	if !strings.HasPrefix(cifrado, prefixoSegredoCifrado) || strings.Contains(cifrado, segredo) {
+		t.Fatalf("cifrarSegredo() = %q, esperado o segredo cifrado com o prefixo", cifrado)
+	}
+	if len(cifrado) > 128 {
+		t.Errorf("cifrarSegredo() tem %d caracteres, mais que a coluna segredo", len(cifrado))
+	}
+
+	outro, _ := cifrarSegredo(chave, 7, segredo)
+	if outro == cifrado {
+		t.Error("cifrarSegredo() repetiu o nonce")
+	}
+
+	if decifrado, erro := decifrarSegredo(chave, 7, cifrado); erro != nil || decifrado != segredo {
+		t.Errorf("decifrarSegredo() = (%q, %v), esperado (%q, nil)", decifrado, erro, segredo)
+	}
+
+	adulterado := cifrado[:len(cifrado)-2] + "AA"
+	if adulterado == cifrado {
+		adulterado = cifrado[:len(cifrado)-2] + "BB"
+	}
+
+	falhas := []struct {
+		nome      string
+		chave     []byte
+		usuarioID uint64
+		guardado  string
+	}{
+		{"outro usuário", chave, 8, cifrado},
+		{"outra chave", []byte(strings.Repeat("x", 32)), 7, cifrado},
+		{"adulterado", chave, 7, adulterado},
+		{"cortado", chave, 7, cifrado[:len(prefixoSegredoCifrado)+4]},
+		{"base64 inválido", chave, 7, prefixoSegredoCifrado + "!!"},
+	}
+
+	for _, falha := range falhas {
+		t.Run(falha.nome, func(t *testing.T) {
+			if _, erro := decifrarSegredo(falha.chave, falha.usuarioID, falha.guardado); erro == nil {
+				t.Error("decifrarSegredo() não retornou erro")
+			}
+		})
+	}
+
+	// Segredos guardados antes da cifragem continuam funcionando
+	if decifrado, erro := decifrarSegredo(chave, 7, segredo); erro != nil || decifrado != segredo {
+		t.Errorf("decifrarSegredo(sem prefixo) = (%q, %v), esperado (%q, nil)", decifrado, erro, segredo)
+	}
+}
@@ -0,0 +1,126 @@
+package webhooks
+
+import (
+	"api/src/banco"
+	"api/src/espera"
+	"api/src/models"
+	"api/src/repository"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// MaximoTentativas é quantas vezes uma entrega é tentada antes de ser marcada como falha
+	MaximoTentativas = 8
+
+	// atrasoInicial é a espera antes da segunda tentativa; as seguintes dobram até atrasoMaximo
+	atrasoInicial = 30 * time.Second
+	atrasoMaximo  = 6 * time.Hour
+
+	intervaloVerificacao = 5 * time.Second
+	tempoLimiteEnvio     = 10 * time.Second
+	entregasPorRodada    = 20
+)
+
+var (
+	acordar = make(chan struct{}, 1)
+	cliente = &http.Client{Timeout: tempoLimiteEnvio}
+)
+
+// Iniciar coloca o despachante de webhooks para rodar em segundo plano
+func Iniciar() {
+	go func() {
+		verificacao := time.NewTicker(intervaloVerificacao)
+		defer verificacao.Stop()
+
+		for {
+			select {
+			case <-verificacao.C:
+			case <-acordar:
+			}
+			processarPendentes()
+		}
+	}()
+}
+
+// Acordar pede ao despachante que procure entregas pendentes sem esperar a próxima verificação
+func Acordar() {
+	select {
+	case acordar <- struct{}{}:
+	default:
+	}
+}
+
+func processarPendentes() {
+	db, erro := banco.Conectar()
+	if erro != nil {
+		log.Printf("erro ao conectar para enviar webhooks: %v", erro)
+		return
+	}
+	defer db.Close()
+
+	repositorio := repository.NovoRepositorioDeWebhooks(db)
+	envios, erro := repositorio.ReservarPendentes(entregasPorRodada, tempoLimiteEnvio*3)
+	if erro != nil {
+		log.Printf("erro ao buscar entregas de webhook pendentes: %v", erro)
+		return
+	}
+
+	for _, envio := range envios {
+		statusHTTP, erro := enviar(envio)
+		if erro == nil {
+			if erro = repositorio.RegistrarSucesso(envio.Entrega.ID, statusHTTP); erro != nil {
+				log.Printf("erro ao registrar entrega de webhook: %v", erro)
+			}
+			continue
+		}
+
+		desistir := envio.Entrega.Tentativas >= MaximoTentativas
+		if erro = repositorio.RegistrarFalha(
+			envio.Entrega.ID, statusHTTP, erro.Error(), espera.Exponencial(envio.Entrega.Tentativas, atrasoInicial, atrasoMaximo), desistir,
+		); erro != nil {
+			log.Printf("erro ao registrar falha de webhook: %v", erro)
+		}
+	}
+
+	// Uma rodada cheia indica que ainda pode haver entregas esperando
+	if len(envios) == entregasPorRodada {
+		Acordar()
+	}
+}
+
+// enviar faz o POST assinado para o webhook; respostas fora da faixa 2xx contam como falha
+func enviar(envio models.EnvioWebhook) (int, error) {
+	corpo := []byte(envio.Entrega.Payload)
+	timestamp := time.Now().Unix()
+
+	requisicao, erro := http.NewRequest(http.MethodPost, envio.URL, bytes.NewReader(corpo))
+	if erro != nil {
+		return 0, erro
+	}
+
+	requisicao.Header.Set("Content-Type", "application/json")
+	requisicao.Header.Set("User-Agent", "Api-RedeSocial-Webhooks/1.0")
+	requisicao.Header.Set("X-Webhook-Evento", envio.Entrega.Evento)
+	requisicao.Header.Set("X-Webhook-Entrega", strconv.FormatUint(envio.Entrega.ID, 10))
+	requisicao.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	requisicao.Header.Set("X-Webhook-Assinatura", Assinar(envio.Segredo, timestamp, corpo))
+
+	resposta, erro := cliente.Do(requisicao)
+	if erro != nil {
+		return 0, erro
+	}
+	defer resposta.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resposta.Body, 64*1024))
+
+	if resposta.StatusCode < 200 || resposta.StatusCode > 299 {
+		return resposta.StatusCode, fmt.Errorf("o webhook respondeu com o status %d", resposta.StatusCode)
+	}
+
+	return resposta.StatusCode, nil
+}
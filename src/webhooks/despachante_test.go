@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"api/src/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestEnviar(t *testing.T) {
+	testes := []struct {
+		nome       string
+		statusHTTP int
+		falha      bool
+	}{
+		{"200 é sucesso", http.StatusOK, false},
+		{"204 é sucesso", http.StatusNoContent, false},
+		{"301 é falha", http.StatusMovedPermanently, true},
+		{"404 é falha", http.StatusNotFound, true},
+		{"500 é falha", http.StatusInternalServerError, true},
+	}
+
+	for _, teste := range testes {
+		t.Run(teste.nome, func(t *testing.T) {
+			var recebida *http.Request
+			var corpoRecebido []byte
+			receptor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				recebida = r
+				corpoRecebido, _ = io.ReadAll(r.Body)
+				w.WriteHeader(teste.statusHTTP)
+			}))
+			defer receptor.Close()
+
+			envio := models.EnvioWebhook{
+				Entrega: models.EntregaWebhook{ID: 42, Evento: models.EventoUsuarioCriado, Payload: `{"evento":"usuario.criado"}`},
+				URL:     receptor.URL,
+				Segredo: "segredo",
+			}
+
+			statusHTTP, erro := enviar(envio)
+			if statusHTTP != teste.statusHTTP {
+				t.Errorf("status = %d, esperado %d", statusHTTP, teste.statusHTTP)
+			}
+			if (erro != nil) != teste.falha {
+				t.Fatalf("erro = %v, esperava falha: %v", erro, teste.falha)
+			}
+
+			if recebida.Method != http.MethodPost || string(corpoRecebido) != envio.Entrega.Payload {
+				t.Errorf("requisição recebida: %s %q", recebida.Method, corpoRecebido)
+			}
+			if recebida.Header.Get("X-Webhook-Evento") != models.EventoUsuarioCriado || recebida.Header.Get("X-Webhook-Entrega") != "42" {
+				t.Errorf("cabeçalhos do evento errados: %v", recebida.Header)
+			}
+
+			timestamp, erro := strconv.ParseInt(recebida.Header.Get("X-Webhook-Timestamp"), 10, 64)
+			if erro != nil {
+				t.Fatalf("timestamp inválido: %v", erro)
+			}
+			if assinatura := Assinar("segredo", timestamp, corpoRecebido); recebida.Header.Get("X-Webhook-Assinatura") != assinatura {
+				t.Errorf("assinatura = %s, esperado %s", recebida.Header.Get("X-Webhook-Assinatura"), assinatura)
+			}
+		})
+	}
+}
+
+func TestEnviarSemReceptor(t *testing.T) {
+	receptor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	receptor.Close()
+
+	statusHTTP, erro := enviar(models.EnvioWebhook{URL: receptor.URL})
+	if erro == nil || statusHTTP != 0 {
+		t.Errorf("enviar para um receptor fora do ar = (%d, %v), esperado (0, erro)", statusHTTP, erro)
+	}
+}
@@ -0,0 +1,50 @@
+package webhooks
+
+import (
+	"api/src/repository"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Payload é o corpo enviado para os webhooks
+type Payload struct {
+	Evento     string      `json:"evento"`
+	OcorridoEm time.Time   `json:"ocorridoEm"`
+	Dados      interface{} `json:"dados"`
+}
+
+// Disparar enfileira o evento para todos os webhooks que o assinam
+func Disparar(db *sql.DB, evento string, ocorridoEm time.Time, dados interface{}) error {
+	payload, erro := json.Marshal(Payload{
+		Evento:     evento,
+		OcorridoEm: ocorridoEm.UTC(),
+		Dados:      dados,
+	})
+	if erro != nil {
+		return erro
+	}
+
+	enfileiradas, erro := repository.NovoRepositorioDeWebhooks(db).Enfileirar(evento, string(payload))
+	if erro != nil {
+		return erro
+	}
+
+	if enfileiradas > 0 {
+		Acordar()
+	}
+	return nil
+}
+
+// Assinar calcula a assinatura HMAC-SHA256 enviada no cabeçalho X-Webhook-Assinatura.
+// O conteúdo assinado é o timestamp do envio, um ponto e o corpo da requisição.
+func Assinar(segredo string, timestamp int64, corpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(corpo)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,26 @@
+package webhooks
+
+import "testing"
+
+func TestAssinar(t *testing.T) {
+	corpo := []byte(`{"evento":"usuario.criado"}`)
+
+	// Valores calculados de forma independente: HMAC-SHA256(segredo, "1700000000." + corpo)
+	testes := []struct {
+		segredo  string
+		esperado string
+	}{
+		{"segredo", "sha256=b67cbeb8dc59d086fb7c94fe1a4ec4f28fccf88df06804fc760f1924b1b6e185"},
+		{"outro-segredo", "sha256=17b4b6e4b3ebb5d437cb9ee713dab5b9319524533f50464a17441f39dfbcf6ea"},
+	}
+
+	for _, teste := range testes {
+		if assinatura := Assinar(teste.segredo, 1700000000, corpo); assinatura != teste.esperado {
+			t.Errorf("Assinar(%q) = %s, esperado %s", teste.segredo, assinatura, teste.esperado)
+		}
+	}
+
+	if Assinar("segredo", 1700000001, corpo) == testes[0].esperado {
+		t.Error("a assinatura deveria mudar com o timestamp")
+	}
+}